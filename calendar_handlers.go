@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ckpt/backend-services/caterings"
+	"github.com/ckpt/backend-services/locations"
+	"github.com/ckpt/backend-services/players"
+	"github.com/ckpt/backend-services/tournaments"
+	"github.com/ckpt/backend-services/utils"
+	"github.com/m4rw3r/uuid"
+	"github.com/zenazn/goji/web"
+)
+
+// How long a tournament is assumed to last in calendar feeds
+const tournamentDuration = 5 * time.Hour
+
+func tournamentCalendarEvents(player uuid.UUID) ([]utils.ICalEvent, error) {
+	tList, err := tournaments.AllTournaments()
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(tList)
+
+	var events []utils.ICalEvent
+	for _, t := range tList {
+		if t.Info.Scheduled.IsZero() {
+			continue
+		}
+		event := utils.ICalEvent{
+			UID:     t.UUID.String() + "@ckpt.no",
+			Summary: "CKPT-turnering",
+			Start:   t.Info.Scheduled,
+			End:     t.Info.Scheduled.Add(tournamentDuration),
+		}
+
+		var description []string
+		description = append(description, fmt.Sprintf("Innsats: %d", t.Info.Stake))
+		if !t.Info.Location.IsZero() {
+			if l, err := locations.LocationByUUID(t.Info.Location); err == nil {
+				event.Location = l.Profile.Name
+				event.Summary = "CKPT-turnering hos " + l.Profile.Name
+				if l.Profile.Coordinates.Lat != 0 || l.Profile.Coordinates.Long != 0 {
+					event.HasGeo = true
+					event.Lat = l.Profile.Coordinates.Lat
+					event.Long = l.Profile.Coordinates.Long
+				}
+			}
+		}
+		if c, err := caterings.CateringByTournament(t.UUID); err == nil && c.Info.Meal != "" {
+			description = append(description, "Mat: "+c.Info.Meal)
+		}
+		if t.Moved && !t.Info.MovedFrom.IsZero() {
+			description = append(description, "Flyttet fra "+t.Info.MovedFrom.In(tournaments.LeagueLocation).Format("02.01.2006 15:04"))
+		}
+
+		if !player.IsZero() {
+			if rsvp, err := t.RSVPByPlayer(player); err == nil {
+				state := "Din påmelding: " + tournaments.RSVPAnswerNames[rsvp.Answer]
+				if rsvp.Comment != "" {
+					state += " (" + rsvp.Comment + ")"
+				}
+				description = append(description, state)
+			} else {
+				description = append(description, "Du har ikke svart på påmeldingen")
+			}
+			for _, a := range t.Noshows {
+				if a.Player == player {
+					description = append(description, "Fravær registrert: "+a.Reason)
+				}
+			}
+		}
+
+		event.Description = strings.Join(description, "\n")
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func getCalendar(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	events, err := tournamentCalendarEvents(uuid.UUID{})
+	if err != nil {
+		return &appError{err, "Cant load tournaments", 500}
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	utils.WriteICal(w, "CKPT", events)
+	return nil
+}
+
+func getPlayerCalendar(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	if err != nil {
+		return &appError{err, "Invalid player", 400}
+	}
+	if !c.Env["authIsAdmin"].(bool) && c.Env["authPlayer"].(uuid.UUID) != pUUID {
+		return &appError{errors.New("Unauthorized"), "Must be player or admin to get player calendar", 403}
+	}
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	events, err := tournamentCalendarEvents(player.UUID)
+	if err != nil {
+		return &appError{err, "Cant load tournaments", 500}
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	utils.WriteICal(w, "CKPT - "+player.Nick, events)
+	return nil
+}
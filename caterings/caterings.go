@@ -71,6 +71,10 @@ func CateringByUUID(uuid uuid.UUID) (*Catering, error) {
 	return storage.Load(uuid)
 }
 
+func CateringByTournament(tournament uuid.UUID) (*Catering, error) {
+	return storage.LoadByTournament(tournament)
+}
+
 func (c *Catering) UpdateInfo(ci Info) error {
 	if err := mergo.MergeWithOverwrite(&c.Info, ci); err != nil {
 		return errors.New(err.Error() + " - Could not update catering info")
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ckpt/backend-services/hands"
+	"github.com/ckpt/backend-services/tournaments"
+	"github.com/m4rw3r/uuid"
+	"github.com/zenazn/goji/web"
+)
+
+func createNewHand(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	nHand := new(hands.Hand)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(nHand); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+	tournament, err := tournaments.TournamentByUUID(nHand.Tournament)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 422}
+	}
+	nHand, err = hands.NewHand(*nHand, c.Env["authPlayer"].(uuid.UUID), tournament.Info.Season)
+	if err != nil {
+		return &appError{err, "Failed to create new hand", 422}
+	}
+	w.Header().Set("Location", "/hands/"+nHand.UUID.String())
+	w.WriteHeader(201)
+	encoder := json.NewEncoder(w)
+	encoder.Encode(nHand)
+	return nil
+}
+
+// List hands, optionally only those from a tournament or season
+func listAllHands(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	query := r.URL.Query()
+
+	var list []*hands.Hand
+	var err error
+	if query.Get("season") != "" {
+		season, _ := strconv.Atoi(query.Get("season"))
+		list, err = hands.HandsBySeason(season)
+	} else {
+		list, err = hands.AllHands()
+	}
+	if err != nil {
+		return &appError{err, "Cant load hands", 500}
+	}
+
+	if query.Get("tournament") != "" {
+		tID, err := uuid.FromString(query.Get("tournament"))
+		if err != nil {
+			return &appError{err, "Invalid tournament", 400}
+		}
+		filtered := []*hands.Hand{}
+		for _, h := range list {
+			if h.Tournament == tID {
+				filtered = append(filtered, h)
+			}
+		}
+		list = filtered
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(list)
+	return nil
+}
+
+func getHand(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	hUUID, err := uuid.FromString(c.URLParams["uuid"])
+	hand, err := hands.HandByUUID(hUUID)
+	if err != nil {
+		return &appError{err, "Cant find hand", 404}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.Encode(hand)
+	return nil
+}
+
+func updateHand(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	hUUID, err := uuid.FromString(c.URLParams["uuid"])
+	hand, err := hands.HandByUUID(hUUID)
+	if err != nil {
+		return &appError{err, "Cant find hand", 404}
+	}
+	if !c.Env["authIsAdmin"].(bool) && c.Env["authPlayer"].(uuid.UUID) != hand.Author {
+		return &appError{errors.New("Unauthorized"), "Must be author or admin to update hand", 403}
+	}
+	tempHand := new(hands.Hand)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(tempHand); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := hand.UpdateHand(*tempHand); err != nil {
+		return &appError{err, "Failed to update hand", 422}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func voteForHand(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	hUUID, err := uuid.FromString(c.URLParams["uuid"])
+	hand, err := hands.HandByUUID(hUUID)
+	if err != nil {
+		return &appError{err, "Cant find hand", 404}
+	}
+
+	if err := hand.Vote(c.Env["authPlayer"].(uuid.UUID)); err != nil {
+		return &appError{err, "Failed to vote for hand", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func removeHandVote(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	hUUID, err := uuid.FromString(c.URLParams["uuid"])
+	hand, err := hands.HandByUUID(hUUID)
+	if err != nil {
+		return &appError{err, "Cant find hand", 404}
+	}
+
+	if err := hand.RemoveVote(c.Env["authPlayer"].(uuid.UUID)); err != nil {
+		return &appError{err, "Failed to remove vote for hand", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func getGoldenHand(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	season, _ := strconv.Atoi(c.URLParams["year"])
+
+	hand, err := hands.HandOfTheSeason(season)
+	if err != nil {
+		return &appError{err, "Cant load hands", 500}
+	}
+	if hand == nil {
+		return &appError{errors.New("No votes"), "No hands in the season have votes", 404}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.Encode(hand)
+	return nil
+}
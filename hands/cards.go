@@ -0,0 +1,182 @@
+package hands
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// A card in two character notation, rank followed by suit, e.g. "Ah",
+// "Td" or "7c". Ranks are 2-9, T, J, Q, K and A, suits c, d, h and s.
+type Card string
+
+const cardRanks = "23456789TJQKA"
+const cardSuits = "cdhs"
+
+type HandCategory int
+
+const (
+	HighCard HandCategory = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+)
+
+var HandCategoryNames = []string{
+	"high card",
+	"pair",
+	"two pair",
+	"three of a kind",
+	"straight",
+	"flush",
+	"full house",
+	"four of a kind",
+	"straight flush",
+}
+
+// The value of the best five cards. Values holds the ranks deciding
+// between hands of the same category, most significant first.
+type HandValue struct {
+	Category HandCategory `json:"category"`
+	Name     string       `json:"name"`
+	Cards    []Card       `json:"cards"`
+	Values   []int        `json:"-"`
+}
+
+// Normalize the notation, accepting lower case ranks and upper case suits
+func ParseCard(s string) (Card, error) {
+	if len(s) != 2 {
+		return "", errors.New("Invalid card " + s + ", use rank and suit like Ah or Td")
+	}
+	rank, suit := strings.ToUpper(s[:1]), strings.ToLower(s[1:])
+	if !strings.Contains(cardRanks, rank) {
+		return "", errors.New("Invalid rank in card " + s)
+	}
+	if !strings.Contains(cardSuits, suit) {
+		return "", errors.New("Invalid suit in card " + s)
+	}
+	return Card(rank + suit), nil
+}
+
+// Rank from 2 to 14 (ace)
+func (c Card) Rank() int {
+	return strings.Index(cardRanks, string(c[0])) + 2
+}
+
+func (c Card) Suit() byte {
+	return c[1]
+}
+
+func (v HandValue) Compare(o HandValue) int {
+	if v.Category != o.Category {
+		return int(v.Category) - int(o.Category)
+	}
+	for i := range v.Values {
+		if i >= len(o.Values) {
+			break
+		}
+		if v.Values[i] != o.Values[i] {
+			return v.Values[i] - o.Values[i]
+		}
+	}
+	return 0
+}
+
+// The high card of a straight in the ranks, sorted high to low with
+// no duplicates, or 0 if there is none. The wheel (A-5) is five high.
+func straightHigh(ranks []int) int {
+	if len(ranks) != 5 {
+		return 0
+	}
+	if ranks[0]-ranks[4] == 4 {
+		return ranks[0]
+	}
+	if ranks[0] == 14 && ranks[1] == 5 && ranks[4] == 2 {
+		return 5
+	}
+	return 0
+}
+
+func evaluateFive(cards []Card) HandValue {
+	counts := make(map[int]int)
+	flush := true
+	for _, c := range cards {
+		counts[c.Rank()] += 1
+		if c.Suit() != cards[0].Suit() {
+			flush = false
+		}
+	}
+
+	// Ranks ordered by how many there are of them, then by rank
+	var ranks []int
+	for r := range counts {
+		ranks = append(ranks, r)
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if counts[ranks[i]] == counts[ranks[j]] {
+			return ranks[i] > ranks[j]
+		}
+		return counts[ranks[i]] > counts[ranks[j]]
+	})
+
+	v := HandValue{Cards: cards, Values: ranks}
+	high := straightHigh(ranks)
+	switch {
+	case high > 0 && flush:
+		v.Category, v.Values = StraightFlush, []int{high}
+	case counts[ranks[0]] == 4:
+		v.Category = FourOfAKind
+	case counts[ranks[0]] == 3 && counts[ranks[1]] == 2:
+		v.Category = FullHouse
+	case flush:
+		v.Category = Flush
+	case high > 0:
+		v.Category, v.Values = Straight, []int{high}
+	case counts[ranks[0]] == 3:
+		v.Category = ThreeOfAKind
+	case counts[ranks[0]] == 2 && counts[ranks[1]] == 2:
+		v.Category = TwoPair
+	case counts[ranks[0]] == 2:
+		v.Category = OnePair
+	default:
+		v.Category = HighCard
+	}
+	v.Name = HandCategoryNames[v.Category]
+	return v
+}
+
+// Find the best five card hand among five to seven cards
+func Evaluate(cards []Card) (HandValue, error) {
+	if len(cards) < 5 || len(cards) > 7 {
+		return HandValue{}, errors.New("Need five to seven cards to evaluate a hand")
+	}
+
+	var best HandValue
+	found := false
+	n := len(cards)
+	// Every way of leaving out n-5 cards
+	for skipA := 0; skipA < n; skipA++ {
+		for skipB := skipA; skipB < n; skipB++ {
+			var five []Card
+			for i, c := range cards {
+				if (n > 5 && i == skipA) || (n > 6 && i == skipB) {
+					continue
+				}
+				five = append(five, c)
+			}
+			if len(five) != 5 {
+				continue
+			}
+			v := evaluateFive(five)
+			if !found || v.Compare(best) > 0 {
+				best, found = v, true
+			}
+		}
+	}
+	return best, nil
+}
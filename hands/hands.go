@@ -0,0 +1,263 @@
+package hands
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/ckpt/backend-services/utils"
+	"github.com/m4rw3r/uuid"
+)
+
+// We use dummy in memory storage for now
+var storage HandStorage = NewRedisHandStorage()
+
+// Init a message queue
+var eventqueue utils.AMQPQueue = utils.NewRMQ(os.Getenv("CKPT_AMQP_URL"), "ckpt.events")
+
+// A player in a hand. Made and Winner are computed from the cards when
+// the hand is stored.
+type HandPlayer struct {
+	Player    uuid.UUID  `json:"player"`
+	HoleCards []Card     `json:"holeCards"`
+	Made      *HandValue `json:"made"`
+	Winner    bool       `json:"winner"`
+}
+
+type Vote struct {
+	Player uuid.UUID `json:"player"`
+	Cast   time.Time `json:"cast"`
+}
+
+// A notable hand from a tournament
+type Hand struct {
+	UUID        uuid.UUID    `json:"uuid"`
+	Tournament  uuid.UUID    `json:"tournament"`
+	Season      int          `json:"season"`
+	Author      uuid.UUID    `json:"author"`
+	Created     time.Time    `json:"created"`
+	Players     []HandPlayer `json:"players"`
+	Board       []Card       `json:"board"`
+	Pot         int          `json:"pot"`
+	Description string       `json:"description"`
+	Votes       []Vote       `json:"votes"`
+}
+
+// A storage interface for Hands
+type HandStorage interface {
+	Store(*Hand) error
+	Delete(uuid.UUID) error
+	Load(uuid.UUID) (*Hand, error)
+	LoadAll() ([]*Hand, error)
+	LoadBySeason(int) ([]*Hand, error)
+}
+
+//
+// Hand related functions and methods
+//
+
+// Normalize and validate the cards of a hand. Every player needs two
+// hole cards, the board is empty, the flop, turn or river, and no card
+// can be dealt twice.
+func validateHand(h *Hand) error {
+	if h.Tournament.IsZero() {
+		return errors.New("Hand needs a tournament")
+	}
+	if len(h.Players) == 0 {
+		return errors.New("Hand needs at least one player")
+	}
+	if h.Pot < 0 {
+		return errors.New("Pot can not be negative")
+	}
+	switch len(h.Board) {
+	case 0, 3, 4, 5:
+	default:
+		return errors.New("Board must have 0, 3, 4 or 5 cards")
+	}
+
+	dealt := make(map[Card]bool)
+	normalize := func(cards []Card) error {
+		for i, c := range cards {
+			card, err := ParseCard(string(c))
+			if err != nil {
+				return err
+			}
+			if dealt[card] {
+				return errors.New("Card " + string(card) + " is dealt more than once")
+			}
+			dealt[card] = true
+			cards[i] = card
+		}
+		return nil
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, p := range h.Players {
+		if p.Player.IsZero() || seen[p.Player] {
+			return errors.New("Players must be given once each")
+		}
+		seen[p.Player] = true
+		if len(p.HoleCards) != 0 && len(p.HoleCards) != 2 {
+			return errors.New("Players must have two hole cards, or none if unknown")
+		}
+		if err := normalize(p.HoleCards); err != nil {
+			return err
+		}
+	}
+	return normalize(h.Board)
+}
+
+// Evaluate the made hand of every player with known hole cards, and
+// mark the best hands as winners
+func (h *Hand) evaluate() {
+	var best *HandValue
+	for i := range h.Players {
+		p := &h.Players[i]
+		p.Made, p.Winner = nil, false
+		if len(p.HoleCards) != 2 || len(h.Board) < 3 {
+			continue
+		}
+		cards := append(append([]Card{}, p.HoleCards...), h.Board...)
+		v, err := Evaluate(cards)
+		if err != nil {
+			continue
+		}
+		p.Made = &v
+		if best == nil || v.Compare(*best) > 0 {
+			best = p.Made
+		}
+	}
+	for i := range h.Players {
+		p := &h.Players[i]
+		p.Winner = best != nil && p.Made != nil && p.Made.Compare(*best) == 0
+	}
+}
+
+func NewHand(handdata Hand, author uuid.UUID, season int) (*Hand, error) {
+	h := new(Hand)
+	*h = handdata
+	if err := validateHand(h); err != nil {
+		return nil, errors.New(err.Error() + " - Could not create hand")
+	}
+	h.UUID, _ = uuid.V4()
+	h.Author = author
+	h.Season = season
+	h.Created = time.Now()
+	h.Votes = []Vote{}
+	h.evaluate()
+	if err := storage.Store(h); err != nil {
+		return nil, errors.New(err.Error() + " - Could not write hand to storage")
+	}
+	eventqueue.Publish(utils.CKPTEvent{
+		Type:    utils.NEWS_EVENT,
+		Subject: "Ny hånd registrert",
+		Message: "Det er registrert en ny hånd på ckpt.no!"})
+	return h, nil
+}
+
+func AllHands() ([]*Hand, error) {
+	return storage.LoadAll()
+}
+
+func HandsBySeason(season int) ([]*Hand, error) {
+	return storage.LoadBySeason(season)
+}
+
+func HandByUUID(uuid uuid.UUID) (*Hand, error) {
+	return storage.Load(uuid)
+}
+
+// Update the cards, pot and description of the hand
+func (h *Hand) UpdateHand(handdata Hand) error {
+	updated := new(Hand)
+	*updated = *h
+	updated.Players = handdata.Players
+	updated.Board = handdata.Board
+	updated.Pot = handdata.Pot
+	updated.Description = handdata.Description
+	if err := validateHand(updated); err != nil {
+		return errors.New(err.Error() + " - Could not update hand")
+	}
+	updated.evaluate()
+	*h = *updated
+	if err := storage.Store(h); err != nil {
+		return errors.New(err.Error() + " - Could not store updated hand")
+	}
+	return nil
+}
+
+func (h *Hand) HasVote(player uuid.UUID) bool {
+	for _, v := range h.Votes {
+		if v.Player == player {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Hand) removeVote(player uuid.UUID) {
+	for i, v := range h.Votes {
+		if v.Player == player {
+			h.Votes = append(h.Votes[:i], h.Votes[i+1:]...)
+			return
+		}
+	}
+}
+
+// Vote for the hand as hand of the season. Every player has one vote
+// per season, so any earlier vote in the season is moved to this hand.
+func (h *Hand) Vote(player uuid.UUID) error {
+	if h.HasVote(player) {
+		return errors.New("Player has already voted for this hand")
+	}
+	season, err := HandsBySeason(h.Season)
+	if err != nil {
+		return errors.New(err.Error() + " - Could not load hands of the season")
+	}
+	for _, other := range season {
+		if other.UUID == h.UUID || !other.HasVote(player) {
+			continue
+		}
+		other.removeVote(player)
+		if err := storage.Store(other); err != nil {
+			return errors.New(err.Error() + " - Could not store hand with moved vote")
+		}
+	}
+
+	h.Votes = append(h.Votes, Vote{Player: player, Cast: time.Now()})
+	if err := storage.Store(h); err != nil {
+		return errors.New(err.Error() + " - Could not store hand with added vote")
+	}
+	return nil
+}
+
+func (h *Hand) RemoveVote(player uuid.UUID) error {
+	if !h.HasVote(player) {
+		return errors.New("Player has not voted for this hand")
+	}
+	h.removeVote(player)
+	if err := storage.Store(h); err != nil {
+		return errors.New(err.Error() + " - Could not store hand with removed vote")
+	}
+	return nil
+}
+
+// The hand with the most votes in the season, the earliest registered
+// wins a tie. Returns nil if no hands in the season have votes.
+func HandOfTheSeason(season int) (*Hand, error) {
+	hands, err := HandsBySeason(season)
+	if err != nil {
+		return nil, err
+	}
+	var best *Hand
+	for _, h := range hands {
+		if len(h.Votes) == 0 {
+			continue
+		}
+		if best == nil || len(h.Votes) > len(best.Votes) ||
+			(len(h.Votes) == len(best.Votes) && h.Created.Before(best.Created)) {
+			best = h
+		}
+	}
+	return best, nil
+}
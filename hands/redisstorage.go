@@ -0,0 +1,95 @@
+package hands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/m4rw3r/uuid"
+	"os"
+	"time"
+)
+
+type RedisHandStorage struct {
+	pool *redigo.Pool
+}
+
+func (rhs *RedisHandStorage) Store(h *Hand) error {
+	conn := rhs.pool.Get()
+	defer conn.Close()
+	b, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if _, err = conn.Do("SADD", "hands", h.UUID); err != nil {
+		return err
+	}
+	if _, err = conn.Do("SET", fmt.Sprintf("hand:%s", h.UUID), b); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rhs *RedisHandStorage) Load(uuid uuid.UUID) (*Hand, error) {
+	conn := rhs.pool.Get()
+	defer conn.Close()
+	b, err := redigo.Bytes(conn.Do("GET", fmt.Sprintf("hand:%s", uuid)))
+	if err != nil {
+		return nil, err
+	}
+	h := new(Hand)
+	if err := json.Unmarshal(b, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (rhs *RedisHandStorage) Delete(uuid uuid.UUID) error {
+	// FIXME: Not implemented yet
+	return errors.New("Not implemented yet")
+}
+
+func (rhs *RedisHandStorage) LoadAll() ([]*Hand, error) {
+	var hands []*Hand
+	conn := rhs.pool.Get()
+	defer conn.Close()
+	b, err := redigo.Strings(conn.Do("SMEMBERS", "hands"))
+	if err != nil {
+		return nil, err
+	}
+	for _, hand := range b {
+		uuid, _ := uuid.FromString(hand)
+		h, err := rhs.Load(uuid)
+		if err != nil {
+			return nil, err
+		}
+		hands = append(hands, h)
+	}
+	return hands, nil
+}
+
+func (rhs *RedisHandStorage) LoadBySeason(season int) ([]*Hand, error) {
+	found := make([]*Hand, 0)
+	hands, err := rhs.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hands {
+		if h.Season == season {
+			found = append(found, h)
+		}
+	}
+	return found, nil
+}
+
+func NewRedisHandStorage() *RedisHandStorage {
+	rhs := new(RedisHandStorage)
+	rhs.pool = &redigo.Pool{
+		MaxIdle:     3,
+		IdleTimeout: 240 * time.Second,
+		Dial: func() (redigo.Conn, error) {
+			return redigo.Dial("tcp", os.Getenv("CKPT_REDIS"))
+		},
+	}
+	return rhs
+}
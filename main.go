@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/rs/cors"
 	"github.com/zenazn/goji"
@@ -73,6 +74,8 @@ func main() {
 		println("Could not initialize event queue. Exiting")
 		os.Exit(1)
 	}
+	// Check for overdue debts every hour
+	players.StartDebtReminders(time.Hour)
 
 	//
 	// HTTP Serving
@@ -86,6 +89,7 @@ func main() {
 	goji.Use(middleware.TokenHandler)
 
 	goji.Post("/login", appHandler(login))
+	goji.Get("/calendar.ics", appHandler(getCalendar))
 
 	goji.Get("/players", appHandler(listAllPlayers))
 	goji.Post("/players", appHandler(createNewPlayer))
@@ -100,14 +104,36 @@ func main() {
 	goji.Put("/players/:uuid/user/password", appHandler(setUserPassword))
 	goji.Put("/players/:uuid/user/settings", appHandler(setUserSettings))
 	goji.Put("/players/:uuid/user/admin", appHandler(setUserAdmin))
+	goji.Put("/players/:uuid/user/treasurer", appHandler(setUserTreasurer))
+	goji.Get("/players/:uuid/user/calendartoken", appHandler(getUserCalendarToken))
+	goji.Put("/players/:uuid/user/calendartoken", appHandler(newUserCalendarToken))
+	goji.Get("/players/:uuid/calendar.ics", appHandler(getPlayerCalendar))
 	goji.Put("/players/:uuid/gossip", appHandler(setPlayerGossip))
 	goji.Patch("/players/:uuid/gossip", appHandler(setPlayerGossip))
 	goji.Delete("/players/:uuid/gossip", appHandler(resetPlayerGossip))
+	goji.Get("/players/:uuid/complaints", appHandler(listPlayerComplaints))
+	goji.Post("/players/:uuid/complaints", appHandler(addPlayerComplaint))
+	goji.Get("/players/:uuid/complaints/:playeruuid", appHandler(getPlayerComplaintsFrom))
+	goji.Put("/players/:uuid/complaints/:complaintuuid/hidden", appHandler(setPlayerComplaintHidden))
+	goji.Delete("/players/:uuid/complaints/:complaintuuid", appHandler(deletePlayerComplaint))
 	goji.Get("/players/:uuid/debts", appHandler(showPlayerDebt))
 	goji.Delete("/players/:uuid/debts", appHandler(resetPlayerDebts))
 	goji.Get("/players/:uuid/credits", appHandler(showPlayerCredits))
 	goji.Post("/players/:uuid/debts", appHandler(addPlayerDebt))
 	goji.Delete("/players/:uuid/debts/:debtuuid", appHandler(settlePlayerDebt))
+	goji.Post("/players/:uuid/debts/:debtuuid/payments", appHandler(addDebtPayment))
+	goji.Put("/players/:uuid/debts/:debtuuid/due", appHandler(setDebtDue))
+	goji.Post("/players/:uuid/debts/:debtuuid/dispute", appHandler(disputePlayerDebt))
+	goji.Delete("/players/:uuid/debts/:debtuuid/dispute", appHandler(resolvePlayerDebtDispute))
+	goji.Get("/players/:uuid/ledger", appHandler(showPlayerLedger))
+	goji.Get("/debts/settlement", appHandler(getDebtSettlement))
+	goji.Post("/debts/settlement", appHandler(applyDebtSettlement))
+	goji.Get("/players/:uuid/rating", appHandler(getPlayerRating))
+	goji.Get("/players/:uuid/compare/:otheruuid", appHandler(comparePlayers))
+	goji.Get("/players/:uuid/career", appHandler(getPlayerCareer))
+	goji.Get("/players/:uuid/winnings", appHandler(getPlayerWinnings))
+	goji.Get("/players/:uuid/awards", appHandler(getPlayerAwards))
+	goji.Get("/players/:uuid/knockouts", appHandler(getPlayerKnockouts))
 	goji.Put("/players/:uuid/votes", appHandler(setPlayerVotes))
 	goji.Patch("/players/:uuid/votes", appHandler(setPlayerVotes))
 	goji.Post("/players/notification_test", appHandler(testPlayerNotify))
@@ -129,18 +155,62 @@ func main() {
 	goji.Put("/tournaments/:uuid/played", appHandler(setTournamentPlayed))
 	goji.Get("/tournaments/:uuid/result", appHandler(getTournamentResult))
 	goji.Put("/tournaments/:uuid/result", appHandler(setTournamentResult))
+	goji.Get("/tournaments/:uuid/result/provisional", appHandler(getProvisionalResult))
+	goji.Post("/tournaments/:uuid/result/confirm", appHandler(confirmTournamentResult))
+	goji.Delete("/tournaments/:uuid/result/provisional", appHandler(rejectTournamentResult))
 	goji.Put("/tournaments/:uuid/bountyhunters", appHandler(setTournamentBountyHunters))
 	goji.Post("/tournaments/:uuid/noshows", appHandler(addTournamentNoShow))
 	goji.Delete("/tournaments/:uuid/noshows/:playeruuid", appHandler(removeTournamentNoShow))
-
+	goji.Get("/tournaments/:uuid/accounting", appHandler(getTournamentAccounting))
+	goji.Post("/tournaments/:uuid/entries", appHandler(addTournamentEntry))
+	goji.Delete("/tournaments/:uuid/entries/:entryuuid", appHandler(removeTournamentEntry))
+	goji.Get("/tournaments/:uuid/bettingpool", appHandler(getTournamentBettingPool))
+	goji.Get("/tournaments/:uuid/bettingpool/results", appHandler(getTournamentBettingPoolResults))
+	goji.Put("/tournaments/:uuid/bettingpool/:playeruuid", appHandler(setTournamentBet))
+	goji.Delete("/tournaments/:uuid/bettingpool/:playeruuid", appHandler(removeTournamentBet))
+	goji.Get("/tournaments/:uuid/players", appHandler(getTournamentPlayers))
+	goji.Get("/tournaments/:uuid/rsvps", appHandler(getTournamentRSVPs))
+	goji.Post("/tournaments/:uuid/rsvps/reminders", appHandler(remindTournamentRSVPs))
+	goji.Put("/tournaments/:uuid/rsvps/:playeruuid", appHandler(setTournamentRSVP))
+	goji.Get("/tournaments/:uuid/schedule", appHandler(getTournamentReschedules))
+	goji.Put("/tournaments/:uuid/schedule", appHandler(rescheduleTournament))
+	goji.Put("/tournaments/:uuid/blinds", appHandler(setTournamentBlinds))
+	goji.Get("/tournaments/:uuid/clock", appHandler(getTournamentClock))
+	goji.Delete("/tournaments/:uuid/clock", appHandler(resetTournamentClock))
+	goji.Get("/tournaments/:uuid/clock/stream", appHandler(streamTournamentClock))
+	goji.Post("/tournaments/:uuid/clock/:action", appHandler(controlTournamentClock))
+	goji.Post("/tournaments/:uuid/knockouts", appHandler(addTournamentKnockout))
+	goji.Delete("/tournaments/:uuid/knockouts/:playeruuid", appHandler(removeTournamentKnockout))
+	goji.Get("/tournaments/:uuid/seating", appHandler(getTournamentSeating))
+	goji.Post("/tournaments/:uuid/seating", appHandler(drawTournamentSeating))
+	goji.Get("/tournaments/:uuid/seating/balance", appHandler(getTournamentBalanceMoves))
+	goji.Post("/tournaments/:uuid/seating/balance", appHandler(balanceTournamentTables))
+	goji.Put("/tournaments/:uuid/seating/checkins/:playeruuid", appHandler(checkInTournamentPlayer))
+	goji.Delete("/tournaments/:uuid/seating/checkins/:playeruuid", appHandler(checkOutTournamentPlayer))
+
+	goji.Get("/standings", appHandler(getFilteredStandings))
+	goji.Get("/knockouts", appHandler(getKnockoutGraph))
 	goji.Get("/seasons", appHandler(listAllSeasons))
 	goji.Get("/seasons/stats", appHandler(getTotalStats))
 	goji.Get("/seasons/standings", appHandler(getTotalStandings))
 	goji.Get("/seasons/titles", appHandler(getTotalTitles))
+	goji.Get("/seasons/records", appHandler(getTotalRecords))
 	goji.Get("/seasons/:year/tournaments", appHandler(listTournamentsBySeason))
 	goji.Get("/seasons/:year/standings", appHandler(getSeasonStandings))
 	goji.Get("/seasons/:year/titles", appHandler(getSeasonTitles))
 	goji.Get("/seasons/:year/stats", appHandler(getSeasonStats))
+	goji.Get("/seasons/:year/progression", appHandler(getSeasonProgression))
+	goji.Get("/seasons/:year/knockouts", appHandler(getSeasonKnockoutGraph))
+	goji.Get("/seasons/:year/goldenhand", appHandler(getGoldenHand))
+	goji.Get("/seasons/:year/simulation", appHandler(getSeasonSimulation))
+	goji.Post("/seasons/:year/whatif", appHandler(seasonWhatIf))
+	goji.Get("/seasons/:year/records", appHandler(getSeasonRecords))
+	goji.Get("/seasons/:year/bettingpool", appHandler(getSeasonBettingPool))
+	goji.Get("/seasons/:year/bettingpool/scoring", appHandler(getSeasonBetScoring))
+	goji.Put("/seasons/:year/bettingpool/scoring", appHandler(setSeasonBetScoring))
+	goji.Get("/seasons/:year/recurrence", appHandler(getSeasonRecurrence))
+	goji.Put("/seasons/:year/recurrence", appHandler(setSeasonRecurrence))
+	goji.Post("/seasons/:year/recurrence/generate", appHandler(generateSeasonSchedule))
 
 	goji.Get("/caterings", appHandler(listAllCaterings))
 	goji.Post("/caterings", appHandler(createNewCatering))
@@ -157,5 +227,12 @@ func main() {
 	goji.Post("/news/:uuid/comments", appHandler(addNewsComment))
 	// TODO: Comment updates/deletion
 
+	goji.Get("/hands", appHandler(listAllHands))
+	goji.Get("/hands/:uuid", appHandler(getHand))
+	goji.Patch("/hands/:uuid", appHandler(updateHand))
+	goji.Post("/hands", appHandler(createNewHand))
+	goji.Post("/hands/:uuid/votes", appHandler(voteForHand))
+	goji.Delete("/hands/:uuid/votes", appHandler(removeHandVote))
+
 	goji.Serve()
 }
@@ -16,6 +16,22 @@ func TokenHandler(c *web.C, h http.Handler) http.Handler {
 			return
 		}
 		authzHeader := r.Header.Get("Authorization")
+		// Calendar clients can not set our auth header, so feeds may use a
+		// separate calendar token given as a query parameter instead
+		if strings.HasSuffix(r.URL.Path, ".ics") && !strings.HasPrefix(authzHeader, "CKPT ") {
+			p, err := players.PlayerByCalendarToken(r.URL.Query().Get("token"))
+			if err != nil || r.Method != "GET" {
+				w.WriteHeader(403)
+				w.Write([]byte("Invalid calendar token"))
+				return
+			}
+			c.Env["authPlayer"] = p.UUID
+			c.Env["authUser"] = p.User.Username
+			c.Env["authIsAdmin"] = false
+			c.Env["authIsTreasurer"] = false
+			h.ServeHTTP(w, r)
+			return
+		}
 		token := strings.TrimPrefix(authzHeader, "CKPT ")
 		if token == authzHeader || len(token) < 6 {
 			w.WriteHeader(403)
@@ -31,6 +47,7 @@ func TokenHandler(c *web.C, h http.Handler) http.Handler {
 		c.Env["authPlayer"] = p.UUID
 		c.Env["authUser"] = p.User.Username
 		c.Env["authIsAdmin"] = p.User.Admin
+		c.Env["authIsTreasurer"] = p.User.Treasurer
 		h.ServeHTTP(w, r)
 	}
 	return http.HandlerFunc(fn)
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/ckpt/backend-services/players"
+	"github.com/ckpt/backend-services/tournaments"
 	"github.com/m4rw3r/uuid"
 	"github.com/zenazn/goji/web"
 	"net/http"
+	"time"
 )
 
 func listAllPlayers(c web.C, w http.ResponseWriter, r *http.Request) *appError {
@@ -15,6 +17,9 @@ func listAllPlayers(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	if err != nil {
 		return &appError{err, "Cant load players", 500}
 	}
+	for _, player := range playerlist {
+		hideComplaints(c, player)
+	}
 	encoder := json.NewEncoder(w)
 	encoder.Encode(playerlist)
 	return nil
@@ -45,6 +50,7 @@ func getPlayer(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	if err != nil {
 		return &appError{err, "Cant find player", 404}
 	}
+	hideComplaints(c, player)
 	encoder := json.NewEncoder(w)
 	encoder.Encode(player)
 	return nil
@@ -265,6 +271,83 @@ func setUserAdmin(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	return nil
 }
 
+func setUserTreasurer(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to set treasurer status", 403}
+	}
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	var treasurerState bool
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&treasurerState); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := player.SetUserTreasurer(treasurerState); err != nil {
+		return &appError{err, "Failed to change settings for user", 500}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func newUserCalendarToken(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+
+	// The token gives access to the feeds, so only the player gets it
+	if c.Env["authPlayer"].(uuid.UUID) != pUUID {
+		return &appError{errors.New("Unauthorized"), "Must be correct user to create calendar token", 403}
+	}
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	token, err := player.NewCalendarToken()
+	if err != nil {
+		return &appError{err, "Failed to create calendar token", 500}
+	}
+
+	writeCalendarToken(w, pUUID, token)
+	return nil
+}
+
+func getUserCalendarToken(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+
+	if c.Env["authPlayer"].(uuid.UUID) != pUUID {
+		return &appError{errors.New("Unauthorized"), "Must be correct user to get calendar token", 403}
+	}
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+	if player.User.CalendarToken == "" {
+		return &appError{errors.New("No calendar token"), "Player has no calendar token", 404}
+	}
+
+	writeCalendarToken(w, pUUID, player.User.CalendarToken)
+	return nil
+}
+
+func writeCalendarToken(w http.ResponseWriter, player uuid.UUID, token string) {
+	encoder := json.NewEncoder(w)
+	encoder.Encode(map[string]string{
+		"token":          token,
+		"calendar":       "/calendar.ics?token=" + token,
+		"playerCalendar": "/players/" + player.String() + "/calendar.ics?token=" + token,
+	})
+}
 
 func showPlayerDebt(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -362,6 +445,171 @@ func settlePlayerDebt(c web.C, w http.ResponseWriter, r *http.Request) *appError
 	return nil
 }
 
+// Creditors, admins and treasurers may register payments
+func addDebtPayment(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	dUUID, err := uuid.FromString(c.URLParams["debtuuid"])
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	debt, err := player.DebtByUUID(dUUID)
+	if err != nil {
+		return &appError{err, "Cant find debt for player", 404}
+	}
+
+	authPlayer := c.Env["authPlayer"].(uuid.UUID)
+	if !c.Env["authIsAdmin"].(bool) && !c.Env["authIsTreasurer"].(bool) && authPlayer != debt.Creditor {
+		return &appError{errors.New("Unauthorized"), "Must be creditor, treasurer or admin to register payment", 403}
+	}
+
+	payment := new(players.Payment)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(payment); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+	payment.RegisteredBy = authPlayer
+
+	if err := player.AddPayment(dUUID, *payment); err != nil {
+		return &appError{err, "Failed to register payment", 422}
+	}
+	w.Header().Set("Location", "/players/"+pUUID.String()+"/debts")
+	w.WriteHeader(201)
+	return nil
+}
+
+func setDebtDue(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	dUUID, err := uuid.FromString(c.URLParams["debtuuid"])
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	debt, err := player.DebtByUUID(dUUID)
+	if err != nil {
+		return &appError{err, "Cant find debt for player", 404}
+	}
+
+	if !c.Env["authIsAdmin"].(bool) && c.Env["authPlayer"].(uuid.UUID) != debt.Creditor {
+		return &appError{errors.New("Unauthorized"), "Must be creditor or admin to set due date", 403}
+	}
+
+	var due time.Time
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&due); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := player.SetDebtDue(dUUID, due); err != nil {
+		return &appError{err, "Failed to set due date", 500}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func disputePlayerDebt(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	dUUID, err := uuid.FromString(c.URLParams["debtuuid"])
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	dispute := new(players.Dispute)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(dispute); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := player.DisputeDebt(dUUID, c.Env["authPlayer"].(uuid.UUID), dispute.Reason); err != nil {
+		return &appError{err, "Failed to dispute debt", 422}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+// The player raising the dispute or an admin may resolve it
+func resolvePlayerDebtDispute(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	dUUID, err := uuid.FromString(c.URLParams["debtuuid"])
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	debt, err := player.DebtByUUID(dUUID)
+	if err != nil {
+		return &appError{err, "Cant find debt for player", 404}
+	}
+	if !debt.IsDisputed() {
+		return &appError{errors.New("Not disputed"), "Debt is not disputed", 404}
+	}
+
+	// The creditor owns the claim, and may resolve disputes of it
+	// whoever raised them
+	authPlayer := c.Env["authPlayer"].(uuid.UUID)
+	if !c.Env["authIsAdmin"].(bool) && authPlayer != debt.Dispute.By && authPlayer != debt.Creditor {
+		return &appError{errors.New("Unauthorized"), "Must have raised the dispute, be the creditor or be admin to resolve it", 403}
+	}
+
+	if err := player.ResolveDispute(dUUID); err != nil {
+		return &appError{err, "Failed to resolve dispute", 500}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func showPlayerLedger(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	if _, err = players.PlayerByUUID(pUUID); err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	ledger, err := players.PlayerLedger(pUUID)
+	if err != nil {
+		return &appError{err, "Cant load ledger for player", 500}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ledger)
+	return nil
+}
+
+func getDebtSettlement(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	settlement, err := players.NewSettlement()
+	if err != nil {
+		return &appError{err, "Cant compute debt settlement", 500}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.Encode(settlement)
+	return nil
+}
+
+func applyDebtSettlement(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !c.Env["authIsAdmin"].(bool) && !c.Env["authIsTreasurer"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin or treasurer to settle debts", 403}
+	}
+	settlement, err := players.ApplySettlement()
+	if err != nil {
+		return &appError{err, "Failed to settle debts", 500}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.Encode(settlement)
+	return nil
+}
+
 func resetPlayerDebts(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	pUUID, err := uuid.FromString(c.URLParams["uuid"])
@@ -487,6 +735,164 @@ func resetPlayerGossip(c web.C, w http.ResponseWriter, r *http.Request) *appErro
 	return nil
 }
 
+func getPlayerRating(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	history, err := tournaments.PlayerRatingHistory(player.UUID)
+	if err != nil {
+		return &appError{err, "Cant load tournaments", 500}
+	}
+
+	rating := tournaments.InitialRating
+	if len(history) > 0 {
+		rating = history[len(history)-1].Rating
+	}
+
+	type PlayerRating struct {
+		Player  uuid.UUID                 `json:"player"`
+		Rating  float64                   `json:"rating"`
+		History []tournaments.RatingPoint `json:"history"`
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(PlayerRating{Player: player.UUID, Rating: rating, History: history})
+	return nil
+}
+
+func comparePlayers(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+	oUUID, err := uuid.FromString(c.URLParams["otheruuid"])
+	other, err := players.PlayerByUUID(oUUID)
+	if err != nil {
+		return &appError{err, "Cant find other player", 404}
+	}
+	if player.UUID == other.UUID {
+		return &appError{errors.New("Same player"), "Cant compare player to itself", 400}
+	}
+
+	tList, err := tournaments.AllTournaments()
+	if err != nil {
+		return &appError{err, "Cant load tournaments", 500}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournaments.Compare(player.UUID, other.UUID, tList))
+	return nil
+}
+
+func getPlayerKnockouts(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	tList, err := tournaments.AllTournaments()
+	if err != nil {
+		return &appError{err, "Cant load tournaments", 500}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournaments.NewKnockoutGraph(tList).Player(player.UUID))
+	return nil
+}
+
+func playerCareer(c web.C) (*tournaments.Career, *appError) {
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return nil, &appError{err, "Cant find player", 404}
+	}
+
+	career, err := tournaments.PlayerCareer(player.UUID)
+	if err != nil {
+		return nil, &appError{err, "Cant load tournaments", 500}
+	}
+	return career, nil
+}
+
+func getPlayerCareer(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	career, appErr := playerCareer(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(career)
+	return nil
+}
+
+func getPlayerWinnings(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	career, appErr := playerCareer(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	type SeasonWinnings struct {
+		Season   int `json:"season"`
+		Winnings int `json:"winnings"`
+		Rank     int `json:"rank"`
+	}
+	type PlayerWinnings struct {
+		Player   uuid.UUID        `json:"player"`
+		Winnings int              `json:"winnings"`
+		Seasons  []SeasonWinnings `json:"seasons"`
+	}
+
+	winnings := PlayerWinnings{Player: career.Player, Seasons: []SeasonWinnings{}}
+	if career.Total != nil {
+		winnings.Winnings = career.Total.Winnings
+	}
+	for _, cs := range career.Seasons {
+		winnings.Seasons = append(winnings.Seasons, SeasonWinnings{cs.Season, cs.Standing.Winnings, cs.Rank})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(winnings)
+	return nil
+}
+
+func getPlayerAwards(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	career, appErr := playerCareer(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	type PlayerAwards struct {
+		Player      uuid.UUID               `json:"player"`
+		Titles      []tournaments.Award     `json:"titles"`
+		BestMonths  int                     `json:"bestMonths"`
+		WorstMonths int                     `json:"worstMonths"`
+		YellowDays  int                     `json:"yellowDays"`
+		Milestones  []tournaments.Milestone `json:"milestones"`
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(PlayerAwards{
+		Player:      career.Player,
+		Titles:      career.Titles,
+		BestMonths:  career.BestMonths,
+		WorstMonths: career.WorstMonths,
+		YellowDays:  career.YellowDays,
+		Milestones:  career.Milestones,
+	})
+	return nil
+}
+
 
 
 func testPlayerNotify(c web.C, w http.ResponseWriter, r *http.Request) *appError {
@@ -500,3 +906,128 @@ func testPlayerNotify(c web.C, w http.ResponseWriter, r *http.Request) *appError
 	w.WriteHeader(204)
 	return nil
 }
+
+// Only admins get to see hidden complaints
+func hideComplaints(c web.C, player *players.Player) {
+	if !c.Env["authIsAdmin"].(bool) {
+		player.Complaints = player.FilterComplaints(players.ComplaintFilter{})
+	}
+}
+
+func addPlayerComplaint(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	nComplaint := new(players.Complaint)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(nComplaint); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	complaint, err := player.AddComplaint(c.Env["authPlayer"].(uuid.UUID), nComplaint.Content)
+	if err != nil {
+		return &appError{err, "Failed to add complaint", 422}
+	}
+	w.Header().Set("Location", "/players/"+pUUID.String()+"/complaints")
+	w.WriteHeader(201)
+	encoder := json.NewEncoder(w)
+	encoder.Encode(complaint)
+	return nil
+}
+
+// List complaints against the player, optionally only those from a
+// given player (?from=) or filed since a date (?since=2006-01-02)
+func listPlayerComplaints(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	filter := players.ComplaintFilter{}
+	if from := r.URL.Query().Get("from"); from != "" {
+		fromUUID, err := uuid.FromString(from)
+		if err != nil {
+			return &appError{err, "Invalid player in from", 400}
+		}
+		filter.From = fromUUID
+	}
+	return writePlayerComplaints(c, w, r, filter)
+}
+
+func getPlayerComplaintsFrom(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	fromUUID, err := uuid.FromString(c.URLParams["playeruuid"])
+	if err != nil {
+		return &appError{err, "Invalid player", 400}
+	}
+	return writePlayerComplaints(c, w, r, players.ComplaintFilter{From: fromUUID})
+}
+
+func writePlayerComplaints(c web.C, w http.ResponseWriter, r *http.Request, filter players.ComplaintFilter) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	if since := r.URL.Query().Get("since"); since != "" {
+		filter.Since, err = time.Parse("2006-01-02", since)
+		if err != nil {
+			return &appError{err, "Invalid since date", 400}
+		}
+	}
+	filter.IncludeHidden = c.Env["authIsAdmin"].(bool)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(player.FilterComplaints(filter))
+	return nil
+}
+
+func setPlayerComplaintHidden(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	cUUID, err := uuid.FromString(c.URLParams["complaintuuid"])
+
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to moderate complaints", 403}
+	}
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	var hidden bool
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&hidden); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := player.SetComplaintHidden(cUUID, hidden); err != nil {
+		return &appError{err, "Failed to moderate complaint", 404}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func deletePlayerComplaint(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	pUUID, err := uuid.FromString(c.URLParams["uuid"])
+	cUUID, err := uuid.FromString(c.URLParams["complaintuuid"])
+
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to delete complaints", 403}
+	}
+
+	player, err := players.PlayerByUUID(pUUID)
+	if err != nil {
+		return &appError{err, "Cant find player", 404}
+	}
+
+	if err := player.DeleteComplaint(cUUID); err != nil {
+		return &appError{err, "Failed to delete complaint", 404}
+	}
+	w.WriteHeader(204)
+	return nil
+}
@@ -0,0 +1,164 @@
+package players
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ckpt/backend-services/utils"
+	"github.com/m4rw3r/uuid"
+)
+
+// A complaint from another player for harassment or similar. Hidden
+// complaints have been moderated away, and are only shown to admins.
+type Complaint struct {
+	UUID      uuid.UUID  `json:"uuid"`
+	From      uuid.UUID  `json:"from"`
+	Content   string     `json:"content"`
+	Created   time.Time  `json:"created"`
+	Hidden    bool       `json:"hidden"`
+	Moderated *time.Time `json:"moderated,omitempty"`
+}
+
+// Complaints used to store the complaining player in full, so From is
+// accepted both as a UUID and as a player object
+func (c *Complaint) UnmarshalJSON(b []byte) error {
+	type complaint Complaint
+	var raw struct {
+		complaint
+		From json.RawMessage `json:"from"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*c = Complaint(raw.complaint)
+	// Unmoderated complaints were stored with a zero time
+	if c.Moderated != nil && c.Moderated.IsZero() {
+		c.Moderated = nil
+	}
+
+	from := bytes.TrimSpace(raw.From)
+	switch {
+	case len(from) == 0 || bytes.Equal(from, []byte("null")):
+		c.From = uuid.UUID{}
+	case from[0] == '{':
+		var player struct {
+			UUID uuid.UUID `json:"uuid"`
+		}
+		if err := json.Unmarshal(from, &player); err != nil {
+			return err
+		}
+		c.From = player.UUID
+	default:
+		if err := json.Unmarshal(from, &c.From); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Filter for listing complaints. Zero values match everything.
+type ComplaintFilter struct {
+	From          uuid.UUID
+	Since         time.Time
+	IncludeHidden bool
+}
+
+func (f ComplaintFilter) matches(c Complaint) bool {
+	if !f.From.IsZero() && c.From != f.From {
+		return false
+	}
+	if !f.Since.IsZero() && c.Created.Before(f.Since) {
+		return false
+	}
+	return f.IncludeHidden || !c.Hidden
+}
+
+func admins() ([]uuid.UUID, error) {
+	all, err := AllPlayers()
+	if err != nil {
+		return nil, err
+	}
+	var admins []uuid.UUID
+	for _, p := range all {
+		if p.User.Admin {
+			admins = append(admins, p.UUID)
+		}
+	}
+	return admins, nil
+}
+
+// File a complaint against the player, and notify the admins
+func (p *Player) AddComplaint(from uuid.UUID, content string) (*Complaint, error) {
+	if from == p.UUID {
+		return nil, errors.New("Players can not complain about themselves")
+	}
+	if content == "" {
+		return nil, errors.New("Complaint needs content")
+	}
+
+	c := Complaint{From: from, Content: content, Created: time.Now()}
+	c.UUID, _ = uuid.V4()
+	p.Complaints = append(p.Complaints, c)
+	if err := storage.Store(p); err != nil {
+		return nil, errors.New(err.Error() + " - Could not store complaint")
+	}
+
+	// An event without restrictions would go to every subscriber
+	if notify, err := admins(); err == nil && len(notify) > 0 {
+		eventqueue.Publish(utils.CKPTEvent{
+			Type:         utils.PLAYER_EVENT,
+			RestrictedTo: notify,
+			Subject:      "Ny klage registrert",
+			Message:      "Det er registrert en ny klage mot " + p.Nick + " på ckpt.no!"})
+	}
+	return &c, nil
+}
+
+// The complaints against the player matching the filter, oldest first
+func (p *Player) FilterComplaints(f ComplaintFilter) []Complaint {
+	complaints := []Complaint{}
+	for _, c := range p.Complaints {
+		if f.matches(c) {
+			complaints = append(complaints, c)
+		}
+	}
+	return complaints
+}
+
+func (p *Player) ComplaintByUUID(complaint uuid.UUID) (*Complaint, error) {
+	for i := range p.Complaints {
+		if p.Complaints[i].UUID == complaint {
+			return &p.Complaints[i], nil
+		}
+	}
+	return nil, errors.New("Complaint not found")
+}
+
+func (p *Player) SetComplaintHidden(complaint uuid.UUID, hidden bool) error {
+	c, err := p.ComplaintByUUID(complaint)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	c.Hidden = hidden
+	c.Moderated = &now
+	if err := storage.Store(p); err != nil {
+		return errors.New(err.Error() + " - Could not store moderated complaint")
+	}
+	return nil
+}
+
+func (p *Player) DeleteComplaint(complaint uuid.UUID) error {
+	for i, c := range p.Complaints {
+		if c.UUID == complaint {
+			p.Complaints = append(p.Complaints[:i], p.Complaints[i+1:]...)
+			if err := storage.Store(p); err != nil {
+				return errors.New(err.Error() + " - Could not store player with deleted complaint")
+			}
+			return nil
+		}
+	}
+	return errors.New("Complaint not found")
+}
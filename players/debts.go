@@ -0,0 +1,457 @@
+package players
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ckpt/backend-services/utils"
+	"github.com/m4rw3r/uuid"
+)
+
+// How often a debitor is reminded of an overdue debt
+const DebtReminderInterval = 7 * 24 * time.Hour
+
+// A (partial) payment of a debt
+type Payment struct {
+	UUID         uuid.UUID `json:"uuid"`
+	Amount       int       `json:"amount"`
+	Paid         time.Time `json:"paid"`
+	RegisteredBy uuid.UUID `json:"registeredBy"`
+}
+
+// A dispute of a debt, raised by the creditor or the debitor
+type Dispute struct {
+	By       uuid.UUID `json:"by"`
+	Reason   string    `json:"reason"`
+	Raised   time.Time `json:"raised"`
+	Resolved time.Time `json:"resolved"`
+}
+
+// An entry in the ledger of a player. Amount is positive when the
+// player is owed money and negative when the player owes it, and
+// Balance is the running sum of the amounts.
+type LedgerEntry struct {
+	When        time.Time `json:"when"`
+	Debt        uuid.UUID `json:"debt"`
+	Counterpart uuid.UUID `json:"counterpart"`
+	Description string    `json:"description"`
+	Amount      int       `json:"amount"`
+	Balance     int       `json:"balance"`
+}
+
+type Ledger []*LedgerEntry
+
+func (l Ledger) Len() int           { return len(l) }
+func (l Ledger) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l Ledger) Less(i, j int) bool { return l[i].When.Before(l[j].When) }
+
+func (d *Debt) AmountPaid() int {
+	paid := 0
+	for _, p := range d.Payments {
+		paid += p.Amount
+	}
+	return paid
+}
+
+// The amount still owed, which is 0 for settled debts
+func (d *Debt) Remaining() int {
+	if !d.Settled.IsZero() {
+		return 0
+	}
+	return d.Amount - d.AmountPaid()
+}
+
+func (d *Debt) IsDisputed() bool {
+	return d.Dispute != nil && d.Dispute.Resolved.IsZero()
+}
+
+// Disputed debts are not overdue until the dispute is resolved
+func (d *Debt) IsOverdue(now time.Time) bool {
+	return d.Settled.IsZero() && !d.Due.IsZero() && d.Due.Before(now) && !d.IsDisputed()
+}
+
+func (p *Player) debtIndex(debtuuid uuid.UUID) (int, error) {
+	for i := range p.Debts {
+		if p.Debts[i].UUID == debtuuid {
+			return i, nil
+		}
+	}
+	return 0, errors.New("Debt not found")
+}
+
+func (p *Player) SetDebtDue(debtuuid uuid.UUID, due time.Time) error {
+	i, err := p.debtIndex(debtuuid)
+	if err != nil {
+		return err
+	}
+	p.Debts[i].Due = due
+	p.Debts[i].LastReminded = time.Time{}
+	if err := storage.Store(p); err != nil {
+		return errors.New(err.Error() + " - Could not store debt due date")
+	}
+	return nil
+}
+
+// Register a payment of (part of) a debt. The debt is settled when it
+// is paid in full.
+func (p *Player) AddPayment(debtuuid uuid.UUID, payment Payment) error {
+	i, err := p.debtIndex(debtuuid)
+	if err != nil {
+		return err
+	}
+	debt := &p.Debts[i]
+	if !debt.Settled.IsZero() {
+		return errors.New("Debt is already settled")
+	}
+	if payment.Amount <= 0 {
+		return errors.New("Payment needs a positive amount")
+	}
+	if payment.Amount > debt.Remaining() {
+		return errors.New("Payment is more than the remaining " + strconv.Itoa(debt.Remaining()))
+	}
+
+	payment.UUID, _ = uuid.V4()
+	if payment.Paid.IsZero() {
+		payment.Paid = time.Now()
+	}
+	debt.Payments = append(debt.Payments, payment)
+	if debt.Remaining() == 0 {
+		debt.Settled = payment.Paid
+	}
+	if err := storage.Store(p); err != nil {
+		return errors.New(err.Error() + " - Could not store debt payment")
+	}
+
+	message := "Det er registrert en delbetaling på " + strconv.Itoa(payment.Amount) +
+		" av et gjeldskrav på ckpt.no. Gjenstående beløp er " + strconv.Itoa(debt.Remaining()) + "."
+	if !debt.Settled.IsZero() {
+		message = "Et gjeldskrav er nå innfridd på ckpt.no!"
+	}
+	eventqueue.Publish(utils.CKPTEvent{
+		Type:         utils.PLAYER_EVENT,
+		RestrictedTo: []uuid.UUID{debt.Debitor, debt.Creditor},
+		Subject:      "Betaling registrert",
+		Message:      message})
+	return nil
+}
+
+// Dispute a debt. Only the creditor and the debitor may dispute it, and
+// only one dispute can be open at a time.
+func (p *Player) DisputeDebt(debtuuid uuid.UUID, by uuid.UUID, reason string) error {
+	i, err := p.debtIndex(debtuuid)
+	if err != nil {
+		return err
+	}
+	debt := &p.Debts[i]
+	if by != debt.Debitor && by != debt.Creditor {
+		return errors.New("Only the creditor or debitor can dispute a debt")
+	}
+	if debt.IsDisputed() {
+		return errors.New("Debt is already disputed")
+	}
+	if reason == "" {
+		return errors.New("Dispute needs a reason")
+	}
+	debt.Dispute = &Dispute{By: by, Reason: reason, Raised: time.Now()}
+	if err := storage.Store(p); err != nil {
+		return errors.New(err.Error() + " - Could not store disputed debt")
+	}
+
+	other := debt.Creditor
+	if by == debt.Creditor {
+		other = debt.Debitor
+	}
+	eventqueue.Publish(utils.CKPTEvent{
+		Type:         utils.PLAYER_EVENT,
+		RestrictedTo: []uuid.UUID{other},
+		Subject:      "Gjeld bestridt",
+		Message:      "Et gjeldskrav du er part i er bestridt på ckpt.no: " + reason})
+	return nil
+}
+
+func (p *Player) ResolveDispute(debtuuid uuid.UUID) error {
+	i, err := p.debtIndex(debtuuid)
+	if err != nil {
+		return err
+	}
+	debt := &p.Debts[i]
+	if !debt.IsDisputed() {
+		return errors.New("Debt is not disputed")
+	}
+	debt.Dispute.Resolved = time.Now()
+	if err := storage.Store(p); err != nil {
+		return errors.New(err.Error() + " - Could not store debt with resolved dispute")
+	}
+	return nil
+}
+
+// Whether any debts have been generated from the tournament
+func HasTournamentDebts(tournament uuid.UUID) (bool, error) {
+	all, err := AllPlayers()
+	if err != nil {
+		return false, errors.New(err.Error() + " - Could not load players to find tournament debts")
+	}
+	for _, p := range all {
+		for _, d := range p.Debts {
+			if d.Tournament == tournament {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Two players owing each other money, ordered by UUID. Amounts between
+// them are positive when the first owes the second.
+type debtPair struct {
+	first, second uuid.UUID
+}
+
+func pairOf(debitor, creditor uuid.UUID) (debtPair, int) {
+	if debitor.String() < creditor.String() {
+		return debtPair{debitor, creditor}, 1
+	}
+	return debtPair{creditor, debitor}, -1
+}
+
+// Replace the debts generated from a tournament with the given debts,
+// e.g. when the result is revised. Debts that are settled or partly
+// paid are kept, and the difference between them and the given debts
+// between the same players is added as correcting debts with the given
+// description, in the reverse direction if too much is owed. All
+// players are stored in a single transaction.
+func ReviseTournamentDebts(tournament uuid.UUID, debts []Debt, correction string) error {
+	var created []Debt
+	err := storage.UpdateAll(func(all []*Player) ([]*Player, error) {
+		created = nil
+		byPlayer := make(map[uuid.UUID]*Player)
+		changed := make(map[uuid.UUID]bool)
+		kept := make(map[debtPair]int)
+		hasKept := make(map[debtPair]bool)
+		for _, p := range all {
+			byPlayer[p.UUID] = p
+			var remaining []Debt
+			for _, d := range p.Debts {
+				switch {
+				case d.Tournament != tournament:
+					remaining = append(remaining, d)
+				case !d.Settled.IsZero() || len(d.Payments) > 0:
+					remaining = append(remaining, d)
+					pair, sign := pairOf(d.Debitor, d.Creditor)
+					kept[pair] += sign * d.Amount
+					hasKept[pair] = true
+				default:
+					changed[p.UUID] = true
+				}
+			}
+			p.Debts = remaining
+		}
+
+		now := time.Now()
+		add := func(d Debt) error {
+			debitor, found := byPlayer[d.Debitor]
+			if !found {
+				return errors.New("Could not find debitor " + d.Debitor.String())
+			}
+			d.UUID, _ = uuid.V4()
+			d.Tournament = tournament
+			d.Created = now
+			d.Payments = []Payment{}
+			debitor.Debts = append(debitor.Debts, d)
+			changed[debitor.UUID] = true
+			created = append(created, d)
+			return nil
+		}
+
+		target := make(map[debtPair]int)
+		for _, d := range debts {
+			pair, sign := pairOf(d.Debitor, d.Creditor)
+			target[pair] += sign * d.Amount
+			if hasKept[pair] {
+				continue
+			}
+			if err := add(d); err != nil {
+				return nil, err
+			}
+		}
+
+		var pairs []debtPair
+		for pair := range hasKept {
+			pairs = append(pairs, pair)
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			if pairs[i].first == pairs[j].first {
+				return pairs[i].second.String() < pairs[j].second.String()
+			}
+			return pairs[i].first.String() < pairs[j].first.String()
+		})
+		for _, pair := range pairs {
+			diff := target[pair] - kept[pair]
+			if diff == 0 {
+				continue
+			}
+			d := Debt{Description: correction, Debitor: pair.first, Creditor: pair.second, Amount: diff}
+			if diff < 0 {
+				d.Debitor, d.Creditor, d.Amount = pair.second, pair.first, -diff
+			}
+			if err := add(d); err != nil {
+				return nil, err
+			}
+		}
+
+		var players []*Player
+		for _, p := range all {
+			if changed[p.UUID] {
+				players = append(players, p)
+			}
+		}
+		return players, nil
+	})
+	if err != nil {
+		return errors.New(err.Error() + " - Could not store tournament debts")
+	}
+
+	// Debts registered as already paid are nothing to be told about
+	notified := make(map[uuid.UUID]bool)
+	for _, d := range created {
+		if d.Settled.IsZero() && !notified[d.Debitor] {
+			notifyNewDebt(d.Debitor)
+			notified[d.Debitor] = true
+		}
+	}
+	return nil
+}
+
+// Remind debitors of overdue debts, at most once per reminder interval
+// for each debt. Returns the number of reminders sent. Only the time of
+// the reminder is stored, on a freshly loaded player, so that payments
+// registered meanwhile are kept.
+func RemindOverdueDebts(now time.Time) (int, error) {
+	all, err := AllPlayers()
+	if err != nil {
+		return 0, errors.New(err.Error() + " - Could not load players to remind of debts")
+	}
+	nicks := make(map[uuid.UUID]string)
+	for _, p := range all {
+		nicks[p.UUID] = p.Nick
+	}
+
+	sent := 0
+	for _, p := range all {
+		reminded := make(map[uuid.UUID]bool)
+		for i := range p.Debts {
+			debt := &p.Debts[i]
+			if !debt.IsOverdue(now) || now.Sub(debt.LastReminded) < DebtReminderInterval {
+				continue
+			}
+			err := eventqueue.Publish(utils.CKPTEvent{
+				Type:         utils.PLAYER_EVENT,
+				RestrictedTo: []uuid.UUID{p.UUID},
+				Subject:      "Påminnelse om forfalt gjeld",
+				Message: fmt.Sprintf("Du skylder %s %d for \"%s\", som forfalt %s. Gjør opp og registrer betalingen på ckpt.no!",
+					nicks[debt.Creditor], debt.Remaining(), debt.Description, debt.Due.Format("02.01.2006"))})
+			if err != nil {
+				continue
+			}
+			reminded[debt.UUID] = true
+			sent += 1
+		}
+		if len(reminded) == 0 {
+			continue
+		}
+		err := storage.Update(p.UUID, func(fresh *Player) error {
+			for i := range fresh.Debts {
+				if reminded[fresh.Debts[i].UUID] {
+					fresh.Debts[i].LastReminded = now
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return sent, errors.New(err.Error() + " - Could not store reminded debts")
+		}
+	}
+	return sent, nil
+}
+
+// Check for overdue debts at the given interval in the background
+func StartDebtReminders(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			sent, err := RemindOverdueDebts(now)
+			if err != nil {
+				fmt.Printf("Could not remind of overdue debts: %s\n", err.Error())
+				continue
+			}
+			if sent > 0 {
+				fmt.Printf("Sent %d reminders of overdue debts\n", sent)
+			}
+		}
+	}()
+}
+
+// The ledger of all debts and credits of the player, with debts
+// counting when they are created and payments when they are paid. Debts
+// settled without registered payments count as paid in full when they
+// were settled.
+func PlayerLedger(player uuid.UUID) (Ledger, error) {
+	all, err := AllPlayers()
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not load players for ledger")
+	}
+
+	ledger := Ledger{}
+	for _, p := range all {
+		for _, d := range p.Debts {
+			var sign int
+			var counterpart uuid.UUID
+			switch player {
+			case d.Debitor:
+				sign, counterpart = -1, d.Creditor
+			case d.Creditor:
+				sign, counterpart = 1, d.Debitor
+			default:
+				continue
+			}
+
+			ledger = append(ledger, &LedgerEntry{
+				When:        d.Created,
+				Debt:        d.UUID,
+				Counterpart: counterpart,
+				Description: d.Description,
+				Amount:      sign * d.Amount,
+			})
+			for _, payment := range d.Payments {
+				ledger = append(ledger, &LedgerEntry{
+					When:        payment.Paid,
+					Debt:        d.UUID,
+					Counterpart: counterpart,
+					Description: "Betaling: " + d.Description,
+					Amount:      -sign * payment.Amount,
+				})
+			}
+			if unpaid := d.Amount - d.AmountPaid(); !d.Settled.IsZero() && unpaid > 0 {
+				ledger = append(ledger, &LedgerEntry{
+					When:        d.Settled,
+					Debt:        d.UUID,
+					Counterpart: counterpart,
+					Description: "Innfridd: " + d.Description,
+					Amount:      -sign * unpaid,
+				})
+			}
+		}
+	}
+
+	sort.Stable(ledger)
+	balance := 0
+	for _, e := range ledger {
+		balance += e.Amount
+		e.Balance = balance
+	}
+	return ledger, nil
+}
@@ -80,6 +80,25 @@ func (dps *DummyPlayerStorage) LoadAll() ([]*Player, error) {
 	return dps.players, nil
 }
 
+func (dps *DummyPlayerStorage) UpdateAll(update func([]*Player) ([]*Player, error)) error {
+	changed, err := update(dps.players)
+	if err != nil {
+		return err
+	}
+	for _, p := range changed {
+		dps.Store(p)
+	}
+	return nil
+}
+
+func (dps *DummyPlayerStorage) Update(uuid uuid.UUID, update func(*Player) error) error {
+	p, err := dps.Load(uuid)
+	if err != nil {
+		return err
+	}
+	return update(p)
+}
+
 func (dps *DummyPlayerStorage) LoadUser(username string) (*User, error) {
 	for _, user := range dps.users {
 		if user.Username == username {
@@ -1,7 +1,9 @@
 package players
 
 import (
+	"crypto/rand"
 	"dario.cat/mergo"
+	"encoding/hex"
 	"errors"
 	"github.com/ckpt/backend-services/utils"
 	"github.com/m4rw3r/uuid"
@@ -53,13 +55,13 @@ type Debt struct {
 	Amount      int       `json:"amount"`
 	Created     time.Time `json:"created"`
 	Settled     time.Time `json:"settled"`
-}
-
-// A complaint from another player
-// for harassment or similar
-type Complaint struct {
-	From    *Player `json:"from"`
-	Content string  `json:"content"`
+	// Set for debts generated from a tournament result
+	Tournament uuid.UUID `json:"tournament"`
+	Due        time.Time `json:"due"`
+	Payments   []Payment `json:"payments"`
+	Dispute    *Dispute  `json:"dispute"`
+	// When the debitor was last reminded of the debt being overdue
+	LastReminded time.Time `json:"lastReminded"`
 }
 
 type Votes struct {
@@ -74,6 +76,8 @@ type PlayerStorage interface {
 	Load(uuid.UUID) (*Player, error)
 	LoadAll() ([]*Player, error)
 	LoadUser(username string) (*User, error)
+	UpdateAll(update func([]*Player) ([]*Player, error)) error
+	Update(uuid uuid.UUID, update func(*Player) error) error
 }
 
 //
@@ -126,6 +130,19 @@ func PlayerByUserToken(token string) (*Player, error) {
 	return nil, errors.New("Could not find player with given token")
 }
 
+func PlayerByCalendarToken(token string) (*Player, error) {
+	players, err := storage.LoadAll()
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not load player by calendar token")
+	}
+	for _, p := range players {
+		if p.User.CalendarToken != "" && p.User.CalendarToken == token {
+			return p, nil
+		}
+	}
+	return nil, errors.New("Could not find player with given calendar token")
+}
+
 func (p *Player) SetUser(user User) error {
 	p.User = user
 	err := storage.Store(p)
@@ -155,6 +172,18 @@ func (p *Player) SetUserSettings(settings UserSettings) error {
 	return nil
 }
 
+func (p *Player) NewCalendarToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New(err.Error() + " - Could not generate calendar token")
+	}
+	p.User.CalendarToken = hex.EncodeToString(b)
+	if err := storage.Store(p); err != nil {
+		return "", errors.New(err.Error() + " - Could not store player calendar token")
+	}
+	return p.User.CalendarToken, nil
+}
+
 func (p *Player) SetUserAdmin(adminStatus bool) error {
 	p.User.Admin = adminStatus
 	if err := storage.Store(p); err != nil {
@@ -163,6 +192,14 @@ func (p *Player) SetUserAdmin(adminStatus bool) error {
 	return nil
 }
 
+func (p *Player) SetUserTreasurer(treasurerStatus bool) error {
+	p.User.Treasurer = treasurerStatus
+	if err := storage.Store(p); err != nil {
+		return errors.New(err.Error() + " - Could not change player user treasurer status")
+	}
+	return nil
+}
+
 func (p *Player) SetProfile(profile Profile) error {
 	p.Profile = profile
 	err := storage.Store(p)
@@ -209,18 +246,30 @@ func (p *Player) AddDebt(d Debt) error {
 	if !d.Settled.IsZero() {
 		newDebt.Settled = d.Settled
 	}
+	newDebt.Due = d.Due
+	// Payments and disputes are registered on the debt afterwards
+	newDebt.Payments = []Payment{}
+	newDebt.Dispute = nil
+	newDebt.LastReminded = time.Time{}
 	newDebt.Debitor = p.UUID
 	p.Debts = append(p.Debts, *newDebt)
 	err := storage.Store(p)
 	if err != nil {
 		return errors.New("Could not add debt")
 	}
+	// Debts registered as already paid are nothing to be told about
+	if newDebt.Settled.IsZero() {
+		notifyNewDebt(p.UUID)
+	}
+	return nil
+}
+
+func notifyNewDebt(debitor uuid.UUID) {
 	eventqueue.Publish(utils.CKPTEvent{
 		Type:         utils.PLAYER_EVENT,
-		RestrictedTo: []uuid.UUID{p.UUID},
+		RestrictedTo: []uuid.UUID{debitor},
 		Subject:      "Gjeld registrert",
 		Message:      "Det er registrert et nytt gjeldskrav mot deg på ckpt.no!"})
-	return nil
 }
 func (p *Player) SettleDebt(debtuuid uuid.UUID) error {
 	for i, debt := range p.Debts {
@@ -239,6 +288,8 @@ func (p *Player) SettleDebt(debtuuid uuid.UUID) error {
 		Message:      "Et av dine gjeldsposter er innfridd på ckpt.no!"})
 	return nil
 }
+
+// Clear all debts of the player
 func (p *Player) ResetDebt() error {
 	p.Debts = []Debt{}
 	err := storage.Store(p)
@@ -14,43 +14,155 @@ type RedisPlayerStorage struct {
 	pool *redigo.Pool
 }
 
+// How many times a transaction is retried when players it depends on
+// are changed by someone else
+const maxTransactionRetries = 10
+
+var errTransactionConflict = errors.New("Players were changed by someone else")
+
 func (rps *RedisPlayerStorage) Store(p *Player) error {
 	conn := rps.pool.Get()
 	defer conn.Close()
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	if err := sendPlayer(conn, p); err != nil {
+		conn.Do("DISCARD")
+		return err
+	}
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+// Queue the commands storing the player
+func sendPlayer(conn redigo.Conn, p *Player) error {
 	b, err := json.Marshal(p)
 	if err != nil {
 		return err
 	}
-	_, err = conn.Do("SADD", "players", p.UUID)
-	if err != nil {
+	if err := conn.Send("SADD", "players", p.UUID); err != nil {
 		return err
 	}
-	_, err = conn.Do("SET", fmt.Sprintf("player:%s", p.UUID), b)
-	if err != nil {
+	if err := conn.Send("SET", fmt.Sprintf("player:%s", p.UUID), b); err != nil {
+		return err
+	}
+	if err := conn.Send("SET", fmt.Sprintf("player:%s:calendartoken", p.UUID), p.User.CalendarToken); err != nil {
 		return err
 	}
 	if p.User.Username != "" {
-		_, err = conn.Do("SADD", "users", p.User.Username)
+		if err := conn.Send("SADD", "users", p.User.Username); err != nil {
+			return err
+		}
+		if err := conn.Send("SET", fmt.Sprintf("user:%s:pwhash", p.User.Username), p.User.password); err != nil {
+			return err
+		}
+		if err := conn.Send("SET", fmt.Sprintf("user:%s:player", p.User.Username), p.UUID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load all players, let update change them and store the players it
+// returns in a single transaction. The players are watched while
+// loaded, and the update is retried from scratch if any of them are
+// stored by someone else in the meantime.
+func (rps *RedisPlayerStorage) UpdateAll(update func([]*Player) ([]*Player, error)) error {
+	return rps.transaction(rps.watchAll, update)
+}
+
+// Load a single player, let update change it and store it in a
+// transaction, retried like for UpdateAll
+func (rps *RedisPlayerStorage) Update(uuid uuid.UUID, update func(*Player) error) error {
+	watch := func(conn redigo.Conn) ([]*Player, error) {
+		if _, err := conn.Do("WATCH", fmt.Sprintf("player:%s", uuid)); err != nil {
+			return nil, err
+		}
+		p, err := loadPlayer(conn, uuid)
 		if err != nil {
+			return nil, err
+		}
+		return []*Player{p}, nil
+	}
+	return rps.transaction(watch, func(players []*Player) ([]*Player, error) {
+		if err := update(players[0]); err != nil {
+			return nil, err
+		}
+		return players, nil
+	})
+}
+
+func (rps *RedisPlayerStorage) transaction(watch func(redigo.Conn) ([]*Player, error), update func([]*Player) ([]*Player, error)) error {
+	conn := rps.pool.Get()
+	defer conn.Close()
+
+	for i := 0; i < maxTransactionRetries; i++ {
+		all, err := watch(conn)
+		if err != nil {
+			conn.Do("UNWATCH")
 			return err
 		}
-		_, err = conn.Do("SET", fmt.Sprintf("user:%s:pwhash", p.User.Username),
-			p.User.password)
+		changed, err := update(all)
 		if err != nil {
+			conn.Do("UNWATCH")
 			return err
 		}
-		_, err = conn.Do("SET", fmt.Sprintf("user:%s:player", p.User.Username),
-			p.UUID)
+		if len(changed) == 0 {
+			conn.Do("UNWATCH")
+			return nil
+		}
+
+		if err := conn.Send("MULTI"); err != nil {
+			return err
+		}
+		for _, p := range changed {
+			if err := sendPlayer(conn, p); err != nil {
+				conn.Do("DISCARD")
+				return err
+			}
+		}
+		reply, err := conn.Do("EXEC")
 		if err != nil {
 			return err
 		}
+		if reply != nil {
+			return nil
+		}
 	}
-	return nil
+	return errTransactionConflict
+}
+
+// Watch and load every player on the connection
+func (rps *RedisPlayerStorage) watchAll(conn redigo.Conn) ([]*Player, error) {
+	if _, err := conn.Do("WATCH", "players"); err != nil {
+		return nil, err
+	}
+	members, err := redigo.Strings(conn.Do("SMEMBERS", "players"))
+	if err != nil {
+		return nil, err
+	}
+	var players []*Player
+	for _, member := range members {
+		uuid, _ := uuid.FromString(member)
+		if _, err := conn.Do("WATCH", fmt.Sprintf("player:%s", uuid)); err != nil {
+			return nil, err
+		}
+		p, err := loadPlayer(conn, uuid)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+	return players, nil
 }
 
 func (rps *RedisPlayerStorage) Load(uuid uuid.UUID) (*Player, error) {
 	conn := rps.pool.Get()
 	defer conn.Close()
+	return loadPlayer(conn, uuid)
+}
+
+func loadPlayer(conn redigo.Conn, uuid uuid.UUID) (*Player, error) {
 	b, err := redigo.Bytes(conn.Do("GET", fmt.Sprintf("player:%s", uuid)))
 	if err != nil {
 		return nil, err
@@ -60,6 +172,21 @@ func (rps *RedisPlayerStorage) Load(uuid uuid.UUID) (*Player, error) {
 	if err != nil {
 		return nil, err
 	}
+	token, err := redigo.String(conn.Do("GET", fmt.Sprintf("player:%s:calendartoken", uuid)))
+	if err == redigo.ErrNil {
+		// Calendar tokens used to be stored with the rest of the user
+		var legacy struct {
+			User struct {
+				CalendarToken string `json:"calendarToken"`
+			} `json:"user"`
+		}
+		json.Unmarshal(b, &legacy)
+		token, err = legacy.User.CalendarToken, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.User.CalendarToken = token
 	if p.User.Username != "" {
 		pwhash, err := redigo.String(conn.Do("GET", fmt.Sprintf("user:%s:pwhash", p.User.Username)))
 		if err != nil {
@@ -0,0 +1,204 @@
+package players
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ckpt/backend-services/utils"
+	"github.com/m4rw3r/uuid"
+)
+
+const settlementDescription = "Oppgjør"
+
+// A payment from one player to another clearing (part of) their balances
+type Transfer struct {
+	From   uuid.UUID `json:"from"`
+	To     uuid.UUID `json:"to"`
+	Amount int       `json:"amount"`
+}
+
+// A plan for clearing all unsettled debts in the league. Balances holds
+// the net remaining amount each player is owed (positive) or owes
+// (negative), and Debts the unsettled debts the transfers replace.
+// Disputed debts are left out until the dispute is resolved.
+type Settlement struct {
+	Balances  map[uuid.UUID]int `json:"balances"`
+	Transfers []Transfer        `json:"transfers"`
+	Debts     []uuid.UUID       `json:"debts"`
+	Computed  time.Time         `json:"computed"`
+}
+
+type balance struct {
+	player uuid.UUID
+	amount int
+}
+
+// Largest amounts first, by player to keep the plan stable
+func sortBalances(b []balance) {
+	sort.Slice(b, func(i, j int) bool {
+		if b[i].amount == b[j].amount {
+			return b[i].player.String() < b[j].player.String()
+		}
+		return b[i].amount > b[j].amount
+	})
+}
+
+// Greedily match the largest debtor with the largest creditor. Every
+// transfer clears at least one of them, so there are at most n-1
+// transfers for n players with a non-zero balance, though not always
+// the fewest possible.
+func settleTransfers(balances map[uuid.UUID]int) []Transfer {
+	var debtors, creditors []balance
+	for player, amount := range balances {
+		switch {
+		case amount < 0:
+			debtors = append(debtors, balance{player, -amount})
+		case amount > 0:
+			creditors = append(creditors, balance{player, amount})
+		}
+	}
+	sortBalances(debtors)
+	sortBalances(creditors)
+
+	transfers := []Transfer{}
+	for len(debtors) > 0 && len(creditors) > 0 {
+		d, c := &debtors[0], &creditors[0]
+		amount := d.amount
+		if c.amount < amount {
+			amount = c.amount
+		}
+		transfers = append(transfers, Transfer{From: d.player, To: c.player, Amount: amount})
+		d.amount -= amount
+		c.amount -= amount
+		if d.amount == 0 {
+			debtors = debtors[1:]
+		}
+		if c.amount == 0 {
+			creditors = creditors[1:]
+		}
+		sortBalances(debtors)
+		sortBalances(creditors)
+	}
+	return transfers
+}
+
+func newSettlement(all []*Player) *Settlement {
+	s := &Settlement{
+		Balances: make(map[uuid.UUID]int),
+		Debts:    []uuid.UUID{},
+		Computed: time.Now(),
+	}
+	for _, p := range all {
+		for _, d := range p.Debts {
+			if !d.Settled.IsZero() || d.IsDisputed() {
+				continue
+			}
+			s.Balances[d.Debitor] -= d.Remaining()
+			s.Balances[d.Creditor] += d.Remaining()
+			s.Debts = append(s.Debts, d.UUID)
+		}
+	}
+	for player, amount := range s.Balances {
+		if amount == 0 {
+			delete(s.Balances, player)
+		}
+	}
+	s.Transfers = settleTransfers(s.Balances)
+	return s
+}
+
+// Compute the settlement of all unsettled debts
+func NewSettlement() (*Settlement, error) {
+	all, err := AllPlayers()
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not load players for settlement")
+	}
+	return newSettlement(all), nil
+}
+
+// Settle the debts of the settlement and replace them with its
+// transfers, computed from the debts as they are now. All players are
+// stored in a single transaction, which is retried if any of them are
+// changed meanwhile, and notified with a single event.
+func ApplySettlement() (*Settlement, error) {
+	var s *Settlement
+	var changed map[uuid.UUID]bool
+	err := storage.UpdateAll(func(all []*Player) ([]*Player, error) {
+		var err error
+		s, changed, err = settle(all, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		var players []*Player
+		for _, p := range all {
+			if changed[p.UUID] {
+				players = append(players, p)
+			}
+		}
+		return players, nil
+	})
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not store settled debts")
+	}
+
+	var notify []uuid.UUID
+	for player := range changed {
+		notify = append(notify, player)
+	}
+	for player := range s.Balances {
+		if !changed[player] {
+			notify = append(notify, player)
+		}
+	}
+	if len(notify) > 0 {
+		eventqueue.Publish(utils.CKPTEvent{
+			Type:         utils.PLAYER_EVENT,
+			RestrictedTo: notify,
+			Subject:      "Gjeld gjort opp",
+			Message: "All utestående gjeld er gjort opp med " + strconv.Itoa(len(s.Transfers)) +
+				" nye gjeldsposter. Se din gjeld på ckpt.no!"})
+	}
+	return s, nil
+}
+
+// Settle the debts of the players in place, returning the settlement
+// and the players changed
+func settle(all []*Player, now time.Time) (*Settlement, map[uuid.UUID]bool, error) {
+	s := newSettlement(all)
+
+	included := make(map[uuid.UUID]bool)
+	for _, debt := range s.Debts {
+		included[debt] = true
+	}
+	byPlayer := make(map[uuid.UUID]*Player)
+	changed := make(map[uuid.UUID]bool)
+	for _, p := range all {
+		byPlayer[p.UUID] = p
+		for i := range p.Debts {
+			if included[p.Debts[i].UUID] {
+				p.Debts[i].Settled = now
+				changed[p.UUID] = true
+			}
+		}
+	}
+	for _, t := range s.Transfers {
+		p, found := byPlayer[t.From]
+		if !found {
+			return nil, nil, errors.New("Could not find debitor " + t.From.String() + " for settlement")
+		}
+		debt := Debt{
+			Debitor:     t.From,
+			Creditor:    t.To,
+			Description: settlementDescription + " " + now.Format("02.01.2006"),
+			Amount:      t.Amount,
+			Created:     now,
+			Payments:    []Payment{},
+		}
+		debt.UUID, _ = uuid.V4()
+		p.Debts = append(p.Debts, debt)
+		changed[p.UUID] = true
+	}
+	return s, changed, nil
+}
@@ -10,10 +10,15 @@ import "golang.org/x/crypto/bcrypt"
 type User struct {
 	Username string `json:"username"`
 	password string
-	Apikey   string       `json:"apikey"`
-	Admin    bool         `json:"admin"`
-	Locked   bool         `json:"locked"`
-	Settings UserSettings `json:"settings"`
+	Apikey   string `json:"apikey"`
+	Admin    bool   `json:"admin"`
+	// Treasurers may settle debts on behalf of the league
+	Treasurer bool         `json:"treasurer"`
+	Locked    bool         `json:"locked"`
+	Settings  UserSettings `json:"settings"`
+	// Token used for calendar feeds, where we can not set auth headers.
+	// Stored apart from the player, and only shown to the player itself.
+	CalendarToken string `json:"-"`
 }
 
 // Create a user
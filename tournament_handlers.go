@@ -1,12 +1,21 @@
 package main
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ckpt/backend-services/locations"
+	"github.com/ckpt/backend-services/players"
 	"github.com/ckpt/backend-services/tournaments"
 	"github.com/m4rw3r/uuid"
 	"github.com/zenazn/goji/web"
@@ -92,6 +101,48 @@ func setTournamentPlayed(c web.C, w http.ResponseWriter, r *http.Request) *appEr
 	return nil
 }
 
+// Validation errors are reported as 422, anything else as a server error
+func resultError(err error, msg string) *appError {
+	if _, invalid := err.(*tournaments.ValidationError); invalid {
+		return &appError{err, "Invalid result", 422}
+	}
+	return &appError{err, msg, 500}
+}
+
+func knownPlayers() ([]uuid.UUID, error) {
+	pList, err := players.AllPlayers()
+	if err != nil {
+		return nil, err
+	}
+	known := []uuid.UUID{}
+	for _, p := range pList {
+		known = append(known, p.UUID)
+	}
+	return known, nil
+}
+
+// Results may be submitted by the host and by players registered for or
+// taking part in the tournament
+func canSubmitResult(player uuid.UUID, tournament *tournaments.Tournament) (bool, error) {
+	if !tournament.Info.Location.IsZero() {
+		location, err := locations.LocationByUUID(tournament.Info.Location)
+		if err != nil {
+			return false, err
+		}
+		if location.Host == player {
+			return true, nil
+		}
+	}
+	for _, p := range tournament.Participants(nil) {
+		if p.Player == player && (p.Status == tournaments.Registered || p.Status == tournaments.CheckedIn || p.Status == tournaments.Finished) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Admins set the result directly, while results from others are
+// provisional until confirmed by another player in the result
 func setTournamentResult(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	tID, err := uuid.FromString(c.URLParams["uuid"])
@@ -101,7 +152,9 @@ func setTournamentResult(c web.C, w http.ResponseWriter, r *http.Request) *appEr
 	}
 
 	type Result struct {
-		Result []uuid.UUID
+		Result        []uuid.UUID
+		BountyHunters tournaments.BountyHunters
+		resultDebtOptions
 	}
 
 	resultData := new(Result)
@@ -110,15 +163,197 @@ func setTournamentResult(c web.C, w http.ResponseWriter, r *http.Request) *appEr
 		return &appError{err, "Invalid JSON", 400}
 	}
 
-	if err := tournament.SetResult(resultData.Result); err != nil {
-		return &appError{err, "Failed to update tournament result", 500}
+	known, err := knownPlayers()
+	if err != nil {
+		return &appError{err, "Cant load players", 500}
+	}
+
+	if !c.Env["authIsAdmin"].(bool) {
+		authPlayer := c.Env["authPlayer"].(uuid.UUID)
+		allowed, err := canSubmitResult(authPlayer, tournament)
+		if err != nil {
+			return &appError{err, "Cant find tournament location", 500}
+		}
+		if !allowed {
+			return &appError{errors.New("Unauthorized"), "Must be host or participant to submit a result", 403}
+		}
+		err = tournament.SubmitResult(resultData.Result, resultData.BountyHunters, authPlayer, known)
+		if _, unauthorized := err.(*tournaments.AuthorizationError); unauthorized {
+			return &appError{err, "Not allowed to submit tournament result", 403}
+		}
+		if err != nil {
+			return resultError(err, "Failed to submit provisional tournament result")
+		}
+		w.WriteHeader(202)
+		encoder := json.NewEncoder(w)
+		encoder.Encode(tournament.Provisional)
+		return nil
+	}
+
+	if err := tournament.ValidateResult(resultData.Result, known); err != nil {
+		return resultError(err, "Invalid result")
+	}
+	if resultData.BountyHunters != nil {
+		if err := tournaments.ValidateBountyHunters(resultData.BountyHunters, tournament.FinishOrderOf(resultData.Result)); err != nil {
+			return resultError(err, "Invalid bounty hunters")
+		}
+		tournament.BountyHunters = resultData.BountyHunters
+	}
+	if err := tournament.SetResult(resultData.Result, known); err != nil {
+		return resultError(err, "Failed to update tournament result")
+	}
+	if err := generateResultDebts(tournament, resultData.resultDebtOptions); err != nil {
+		return &appError{err, "Failed to generate debts from tournament result", 500}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+// Options for generating debts from a result. Players in Paid have
+// already paid, and get their debts settled right away, which only
+// admins may register.
+type resultDebtOptions struct {
+	Debts bool        `json:"debts"`
+	Paid  []uuid.UUID `json:"paid"`
+}
+
+func (opts resultDebtOptions) authorize(c web.C) *appError {
+	if len(opts.Paid) > 0 && !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to register debts as paid", 403}
+	}
+	return nil
+}
+
+// Generate debts from the result of the tournament if asked to, or if
+// debts were generated from an earlier result. Those are replaced,
+// keeping debts already (partly) paid and correcting for the difference.
+func generateResultDebts(t *tournaments.Tournament, opts resultDebtOptions) error {
+	if !opts.Debts {
+		existing, err := players.HasTournamentDebts(t.UUID)
+		if err != nil {
+			return err
+		}
+		if !existing {
+			return nil
+		}
+	}
+
+	paid := make(map[uuid.UUID]bool)
+	for _, player := range opts.Paid {
+		paid[player] = true
+	}
+	when := t.Info.Scheduled.Format("02.01.2006")
+	debts := []players.Debt{}
+	for _, rd := range t.ResultDebts() {
+		debt := players.Debt{
+			Debitor:     rd.Debitor,
+			Creditor:    rd.Creditor,
+			Description: "Turnering " + when,
+			Amount:      rd.Amount,
+		}
+		if rd.Bounty {
+			debt.Description = "Bounty, turnering " + when
+		}
+		if paid[rd.Debitor] {
+			debt.Settled = time.Now()
+		}
+		debts = append(debts, debt)
+	}
+	return players.ReviseTournamentDebts(t.UUID, debts, "Korreksjon, turnering "+when)
+}
+
+func getProvisionalResult(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+	if tournament.Provisional == nil {
+		return &appError{errors.New("No provisional result"), "Tournament has no provisional result", 404}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournament.Provisional)
+	return nil
+}
+
+func confirmTournamentResult(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	// The debt options are optional, so an empty body is fine
+	opts := new(resultDebtOptions)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(opts); err != nil && err != io.EOF {
+		return &appError{err, "Invalid JSON", 400}
+	}
+	if aerr := opts.authorize(c); aerr != nil {
+		return aerr
+	}
+
+	if tournament.Provisional == nil {
+		return &appError{errors.New("No provisional result"), "Tournament has no provisional result", 404}
+	}
+	known, err := knownPlayers()
+	if err != nil {
+		return &appError{err, "Cant load players", 500}
+	}
+	err = tournament.ConfirmResult(c.Env["authPlayer"].(uuid.UUID), c.Env["authIsAdmin"].(bool), known)
+	if err != nil {
+		if _, unauthorized := err.(*tournaments.AuthorizationError); unauthorized {
+			return &appError{err, "Not allowed to confirm tournament result", 403}
+		}
+		return resultError(err, "Failed to confirm tournament result")
+	}
+	if err := generateResultDebts(tournament, *opts); err != nil {
+		return &appError{err, "Failed to generate debts from tournament result", 500}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+// The submitter, an admin or any player in the result may reject it
+func rejectTournamentResult(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+	if tournament.Provisional == nil {
+		return &appError{errors.New("No provisional result"), "Tournament has no provisional result", 404}
+	}
+
+	authPlayer := c.Env["authPlayer"].(uuid.UUID)
+	inResult := false
+	for _, p := range tournament.Provisional.Result {
+		if p == authPlayer {
+			inResult = true
+		}
+	}
+	if !c.Env["authIsAdmin"].(bool) && !inResult && tournament.Provisional.SubmittedBy != authPlayer {
+		return &appError{errors.New("Unauthorized"), "Must be admin or in the result to reject it", 403}
+	}
+
+	if err := tournament.RejectResult(); err != nil {
+		return &appError{err, "Failed to reject tournament result", 500}
 	}
 	w.WriteHeader(204)
 	return nil
 }
 
+// Bounty hunters count like the result, so only admins may change them
+// without going through confirmation
 func setTournamentBountyHunters(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to set bounty hunters", 403}
+	}
 	tID, err := uuid.FromString(c.URLParams["uuid"])
 	tournament, err := tournaments.TournamentByUUID(tID)
 	if err != nil {
@@ -132,7 +367,7 @@ func setTournamentBountyHunters(c web.C, w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := tournament.SetBountyHunters(bhData); err != nil {
-		return &appError{err, "Failed to update tournament bounty hunters", 500}
+		return resultError(err, "Failed to update tournament bounty hunters")
 	}
 	w.WriteHeader(204)
 	return nil
@@ -226,38 +461,268 @@ func listTournamentsBySeason(c web.C, w http.ResponseWriter, r *http.Request) *a
 
 func getSeasonStandings(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	start := time.Now()
 	season, _ := strconv.Atoi(c.URLParams["year"])
-	sortedStandings := tournaments.SeasonStandings(season)
+	sortedStandings, err := tournaments.SeasonStandings(season)
+	if err != nil {
+		return &appError{err, "Cant compute season standings", 500}
+	}
 
-	encoder := json.NewEncoder(w)
-	encoder.Encode(sortedStandings)
+	return writeCachedJSON(w, r, []int{season}, start, sortedStandings)
+}
+
+// Write stats computed from the season cache, with an ETag so clients
+// can skip downloading unchanged stats, and timings of the computation
+func writeCachedJSON(w http.ResponseWriter, r *http.Request, seasons []int, start time.Time, v interface{}) *appError {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return &appError{err, "Cant encode response", 500}
+	}
+	sum := sha1.Sum(b)
+	etag := "\"" + hex.EncodeToString(sum[:]) + "\""
+
+	computed, duration, err := tournaments.CacheInfo(seasons)
+	if err != nil {
+		return &appError{err, "Cant compute stats", 500}
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("X-Computed-At", computed.UTC().Format(http.TimeFormat))
+	w.Header().Set("Server-Timing", fmt.Sprintf("compute;dur=%.1f, total;dur=%.1f",
+		duration.Seconds()*1000, time.Since(start).Seconds()*1000))
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	w.Write(b)
 	return nil
 }
 
 func getSeasonStats(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	start := time.Now()
+	season, _ := strconv.Atoi(c.URLParams["year"])
+
+	seasonStats, err := tournaments.SeasonStats([]int{season})
+	if err != nil {
+		return &appError{err, "Cant compute season stats", 500}
+	}
+
+	return writeCachedJSON(w, r, []int{season}, start, seasonStats)
+}
+
+func getSeasonTitles(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	start := time.Now()
+	season, _ := strconv.Atoi(c.URLParams["year"])
+
+	seasonTitles, err := tournaments.Titles([]int{season})
+	if err != nil {
+		return &appError{err, "Cant compute season titles", 500}
+	}
+
+	return writeCachedJSON(w, r, []int{season}, start, seasonTitles)
+}
+
+func getSeasonProgression(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	start := time.Now()
 	season, _ := strconv.Atoi(c.URLParams["year"])
 
-	seasonStats := tournaments.SeasonStats([]int{season})
+	progression, err := tournaments.SeasonProgression(season)
+	if err != nil {
+		return &appError{err, "Cant compute season progression", 500}
+	}
+
+	return writeCachedJSON(w, r, []int{season}, start, progression)
+}
+
+func getSeasonSimulation(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	season, _ := strconv.Atoi(c.URLParams["year"])
+	query := r.URL.Query()
+
+	runs := tournaments.DefaultSimulationRuns
+	if query.Get("runs") != "" {
+		n, err := strconv.Atoi(query.Get("runs"))
+		if err != nil || n <= 0 || n > tournaments.MaxSimulationRuns {
+			return &appError{errors.New("Invalid number of runs"), "Runs must be between 1 and " + strconv.Itoa(tournaments.MaxSimulationRuns), 400}
+		}
+		runs = n
+	}
+	seed := time.Now().UnixNano()
+	if query.Get("seed") != "" {
+		n, err := strconv.ParseInt(query.Get("seed"), 10, 64)
+		if err != nil {
+			return &appError{err, "Invalid seed", 400}
+		}
+		seed = n
+	}
+
+	simulation, err := tournaments.SimulateSeason(season, runs, seed)
+	if err != nil {
+		return &appError{err, "Cant find tournaments", 404}
+	}
 
 	encoder := json.NewEncoder(w)
-	encoder.Encode(seasonStats)
+	encoder.Encode(simulation)
 	return nil
 }
 
-func getSeasonTitles(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+func seasonWhatIf(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	season, _ := strconv.Atoi(c.URLParams["year"])
+
+	type WhatIfRequest struct {
+		Results map[uuid.UUID]tournaments.Result `json:"results"`
+	}
+	whatIfReq := new(WhatIfRequest)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(whatIfReq); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	whatIf, err := tournaments.SeasonWhatIf(season, whatIfReq.Results)
+	if err != nil {
+		return &appError{err, "Cant compute hypothetical standings", 400}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(whatIf)
+	return nil
+}
+
+func writeKnockoutGraph(w http.ResponseWriter, r *http.Request, tList tournaments.Tournaments) *appError {
+	graph := tournaments.NewKnockoutGraph(tList)
+
+	if r.URL.Query().Get("format") == "dot" {
+		pList, err := players.AllPlayers()
+		if err != nil {
+			return &appError{err, "Cant load players", 500}
+		}
+		names := make(map[uuid.UUID]string)
+		for _, p := range pList {
+			names[p.UUID] = p.Profile.Name
+		}
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		w.Write(graph.DOT(names))
+		return nil
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(graph)
+	return nil
+}
+
+func getKnockoutGraph(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tList, err := tournaments.AllTournaments()
+	if err != nil {
+		return &appError{err, "Cant find tournaments", 404}
+	}
+	return writeKnockoutGraph(w, r, tList)
+}
+
+func getSeasonKnockoutGraph(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	season, _ := strconv.Atoi(c.URLParams["year"])
+	tList, err := tournaments.TournamentsBySeason(season)
+	if err != nil {
+		return &appError{err, "Cant find tournaments", 404}
+	}
+	return writeKnockoutGraph(w, r, tList)
+}
+
+func uuidsFromQuery(value string) ([]uuid.UUID, error) {
+	var uuids []uuid.UUID
+	if value == "" {
+		return uuids, nil
+	}
+	for _, s := range strings.Split(value, ",") {
+		u, err := uuid.FromString(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		uuids = append(uuids, u)
+	}
+	return uuids, nil
+}
+
+func getFilteredStandings(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	query := r.URL.Query()
+
+	var filter tournaments.StandingsFilter
+	var err error
+	if from := query.Get("from"); from != "" {
+		filter.From, err = time.ParseInLocation("2006-01-02", from, tournaments.LeagueLocation)
+		if err != nil {
+			return &appError{err, "Invalid from date, use YYYY-MM-DD", 400}
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		filter.To, err = time.ParseInLocation("2006-01-02", to, tournaments.LeagueLocation)
+		if err != nil {
+			return &appError{err, "Invalid to date, use YYYY-MM-DD", 400}
+		}
+		// Include tournaments on the last day
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+	if filter.Players, err = uuidsFromQuery(query.Get("players")); err != nil {
+		return &appError{err, "Invalid player list", 400}
+	}
+	if filter.Locations, err = uuidsFromQuery(query.Get("locations")); err != nil {
+		return &appError{err, "Invalid location list", 400}
+	}
+
+	standings, err := tournaments.FilteredStandings(filter)
+	if err != nil {
+		return &appError{err, "Cant find tournaments", 404}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(standings)
+	return nil
+}
+
+func recordWinsFromQuery(r *http.Request) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("wins"))
+	if err != nil || n <= 0 {
+		return tournaments.DefaultRecordWins
+	}
+	return n
+}
+
+func getSeasonRecords(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	season, _ := strconv.Atoi(c.URLParams["year"])
 
-	seasonTitles := tournaments.Titles([]int{season})
+	records, err := tournaments.SeasonRecords(season, recordWinsFromQuery(r))
+	if err != nil {
+		return &appError{err, "Cant find tournaments", 404}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(records)
+	return nil
+}
+
+func getTotalRecords(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	records, err := tournaments.TotalRecords(recordWinsFromQuery(r))
+	if err != nil {
+		return &appError{err, "Cant find tournaments", 404}
+	}
 
 	encoder := json.NewEncoder(w)
-	encoder.Encode(seasonTitles)
+	encoder.Encode(records)
 	return nil
 }
 
 func getTotalStandings(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	start := time.Now()
 
 	tList, err := tournaments.AllTournaments()
 	if err != nil {
@@ -266,15 +731,17 @@ func getTotalStandings(c web.C, w http.ResponseWriter, r *http.Request) *appErro
 
 	seasons := tList.Seasons()
 
-	totalStandings := tournaments.TotalStandings(seasons)
+	totalStandings, err := tournaments.TotalStandings(seasons)
+	if err != nil {
+		return &appError{err, "Cant compute standings", 500}
+	}
 
-	encoder := json.NewEncoder(w)
-	encoder.Encode(totalStandings)
-	return nil
+	return writeCachedJSON(w, r, seasons, start, totalStandings)
 }
 
 func getTotalStats(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	start := time.Now()
 	tList, err := tournaments.AllTournaments()
 	if err != nil {
 		return &appError{err, "Cant find tournaments", 404}
@@ -282,15 +749,17 @@ func getTotalStats(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 
 	seasons := tList.Seasons()
 
-	fullStats := tournaments.SeasonStats(seasons)
+	fullStats, err := tournaments.SeasonStats(seasons)
+	if err != nil {
+		return &appError{err, "Cant compute stats", 500}
+	}
 
-	encoder := json.NewEncoder(w)
-	encoder.Encode(fullStats)
-	return nil
+	return writeCachedJSON(w, r, seasons, start, fullStats)
 }
 
 func getTotalTitles(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	start := time.Now()
 	tList, err := tournaments.AllTournaments()
 	if err != nil {
 		return &appError{err, "Cant find tournaments", 404}
@@ -298,9 +767,761 @@ func getTotalTitles(c web.C, w http.ResponseWriter, r *http.Request) *appError {
 
 	seasons := tList.Seasons()
 
-	allTitles := tournaments.Titles(seasons)
+	allTitles, err := tournaments.Titles(seasons)
+	if err != nil {
+		return &appError{err, "Cant compute titles", 500}
+	}
+
+	return writeCachedJSON(w, r, seasons, start, allTitles)
+}
+
+func getTournamentAccounting(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
 
 	encoder := json.NewEncoder(w)
-	encoder.Encode(allTitles)
+	encoder.Encode(tournament.Accounting())
+	return nil
+}
+
+func addTournamentEntry(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	// Entries change winnings and generated debts, so only admins and
+	// the treasurer may register them
+	if !c.Env["authIsAdmin"].(bool) && !c.Env["authIsTreasurer"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin or treasurer to add entries", 403}
+	}
+
+	entryData := new(tournaments.Entry)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(entryData); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := tournament.AddEntry(*entryData); err != nil {
+		return &appError{err, "Failed to add tournament entry", 500}
+	}
+	w.Header().Set("Location", "/tournaments/"+tID.String()+"/accounting")
+	w.WriteHeader(201)
+	return nil
+}
+
+func removeTournamentEntry(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to remove entries", 403}
+	}
+
+	eID, err := uuid.FromString(c.URLParams["entryuuid"])
+	if err := tournament.RemoveEntry(eID); err != nil {
+		return &appError{err, "Failed to remove tournament entry", 404}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func getTournamentBettingPool(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	// Keep other predictions hidden until the betting pool is locked
+	bets := tournament.Bets
+	if !tournament.BetsLocked() && !c.Env["authIsAdmin"].(bool) {
+		bets = []tournaments.Bet{}
+		if bet, err := tournament.BetByPlayer(c.Env["authPlayer"].(uuid.UUID)); err == nil {
+			bets = append(bets, *bet)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(bets)
+	return nil
+}
+
+func setTournamentBet(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	pID, err := uuid.FromString(c.URLParams["playeruuid"])
+	if !c.Env["authIsAdmin"].(bool) && pID != c.Env["authPlayer"].(uuid.UUID) {
+		return &appError{errors.New("Unauthorized"), "Must be given player or admin to set bet", 403}
+	}
+
+	betData := new(tournaments.Bet)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(betData); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := tournament.PlaceBet(pID, betData.Prediction); err != nil {
+		return &appError{err, "Failed to set bet for tournament", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func removeTournamentBet(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	pID, err := uuid.FromString(c.URLParams["playeruuid"])
+	if !c.Env["authIsAdmin"].(bool) && pID != c.Env["authPlayer"].(uuid.UUID) {
+		return &appError{errors.New("Unauthorized"), "Must be given player or admin to withdraw bet", 403}
+	}
+
+	if err := tournament.WithdrawBet(pID); err != nil {
+		return &appError{err, "Failed to withdraw bet for tournament", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func getTournamentBettingPoolResults(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	scoring, err := tournaments.SeasonBetScoring(tournament.Info.Season)
+	if err != nil {
+		return &appError{err, "Cant load bet scoring", 500}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournament.ScoreBets(scoring))
+	return nil
+}
+
+func getSeasonBettingPool(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	season, _ := strconv.Atoi(c.URLParams["year"])
+
+	standings, err := tournaments.SeasonBettingPool(season)
+	if err != nil {
+		return &appError{err, "Cant find tournaments", 404}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(standings)
+	return nil
+}
+
+func getSeasonBetScoring(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	season, _ := strconv.Atoi(c.URLParams["year"])
+
+	scoring, err := tournaments.SeasonBetScoring(season)
+	if err != nil {
+		return &appError{err, "Cant load bet scoring", 500}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(scoring)
+	return nil
+}
+
+func setSeasonBetScoring(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to set bet scoring", 403}
+	}
+	season, err := strconv.Atoi(c.URLParams["year"])
+	if err != nil {
+		return &appError{err, "Invalid season", 400}
+	}
+
+	scoring := new(tournaments.BetScoring)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(scoring); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := tournaments.SetSeasonBetScoring(season, *scoring); err != nil {
+		return &appError{err, "Failed to set bet scoring", 422}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func getTournamentRSVPs(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	type RSVPList struct {
+		RSVPs     []tournaments.RSVP    `json:"rsvps"`
+		Headcount tournaments.Headcount `json:"headcount"`
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(RSVPList{RSVPs: tournament.RSVPs, Headcount: tournament.Headcount()})
+	return nil
+}
+
+func setTournamentRSVP(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	pID, err := uuid.FromString(c.URLParams["playeruuid"])
+	if !c.Env["authIsAdmin"].(bool) && pID != c.Env["authPlayer"].(uuid.UUID) {
+		return &appError{errors.New("Unauthorized"), "Must be given player or admin to set RSVP", 403}
+	}
+
+	rsvpData := new(tournaments.RSVP)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(rsvpData); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := tournament.SetRSVP(pID, rsvpData.Answer, rsvpData.Comment); err != nil {
+		return &appError{err, "Failed to set RSVP for tournament", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func remindTournamentRSVPs(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to send RSVP reminders", 403}
+	}
+
+	active, err := activePlayers()
+	if err != nil {
+		return &appError{err, "Cant load players", 500}
+	}
+
+	reminded, err := tournament.RemindMissingRSVPs(active)
+	if err != nil {
+		return &appError{err, "Failed to send RSVP reminders", 500}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(map[string][]uuid.UUID{"reminded": reminded})
+	return nil
+}
+
+// List every active player with their status in the tournament,
+// optionally only those with the given status
+func getTournamentPlayers(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	active, err := activePlayers()
+	if err != nil {
+		return &appError{err, "Cant load players", 500}
+	}
+
+	participants := tournament.Participants(active)
+	if status := r.URL.Query().Get("status"); status != "" {
+		filtered := tournaments.Participants{}
+		for _, p := range participants {
+			if tournaments.ParticipantStatusNames[p.Status] == status {
+				filtered = append(filtered, p)
+			}
+		}
+		participants = filtered
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(participants)
+	return nil
+}
+
+func activePlayers() ([]uuid.UUID, error) {
+	playerList, err := players.AllPlayers()
+	if err != nil {
+		return nil, err
+	}
+	var active []uuid.UUID
+	for _, p := range playerList {
+		if p.Active {
+			active = append(active, p.UUID)
+		}
+	}
+	return active, nil
+}
+
+func getSeasonRecurrence(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	season, _ := strconv.Atoi(c.URLParams["year"])
+	recurrence, err := tournaments.RecurrenceBySeason(season)
+	if err != nil {
+		return &appError{err, "Cant find recurrence for season", 404}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(recurrence)
+	return nil
+}
+
+func setSeasonRecurrence(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to set season recurrence", 403}
+	}
+	season, err := strconv.Atoi(c.URLParams["year"])
+	if err != nil {
+		return &appError{err, "Invalid season", 400}
+	}
+
+	recurrence := new(tournaments.Recurrence)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(recurrence); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+	recurrence.Season = season
+
+	if err := tournaments.SetRecurrence(recurrence); err != nil {
+		return &appError{err, "Failed to set season recurrence", 500}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func generateSeasonSchedule(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to generate schedule", 403}
+	}
+	season, _ := strconv.Atoi(c.URLParams["year"])
+
+	created, err := tournaments.GenerateSchedule(season)
+	if err != nil {
+		return &appError{err, "Failed to generate schedule", 500}
+	}
+
+	w.WriteHeader(201)
+	encoder := json.NewEncoder(w)
+	encoder.Encode(map[string]tournaments.Tournaments{"tournaments": created})
+	return nil
+}
+
+func rescheduleTournament(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to reschedule tournament", 403}
+	}
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	type RescheduleRequest struct {
+		Scheduled time.Time `json:"scheduled"`
+		Reason    string    `json:"reason"`
+	}
+
+	reschedule := new(RescheduleRequest)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(reschedule); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := tournament.Reschedule(reschedule.Scheduled, reschedule.Reason, c.Env["authPlayer"].(uuid.UUID)); err != nil {
+		return &appError{err, "Failed to reschedule tournament", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func getTournamentReschedules(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournament.Reschedules)
+	return nil
+}
+
+func setTournamentBlinds(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to set blind structure", 403}
+	}
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	var blinds []tournaments.BlindLevel
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&blinds); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if err := tournament.SetBlinds(blinds); err != nil {
+		return &appError{err, "Failed to set blind structure", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func getTournamentClock(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournament.ClockState())
+	return nil
+}
+
+// Push clock state to the client as server-sent events, both when the
+// clock is changed and every second to keep the countdown in sync.
+func streamTournamentClock(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return &appError{errors.New("Streaming unsupported"), "Cant stream clock", 500}
+	}
+
+	changes, stop := tournaments.WatchClock(tID)
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		state, _ := json.Marshal(tournament.ClockState())
+		if _, err := w.Write([]byte("event: clock\ndata: " + string(state) + "\n\n")); err != nil {
+			return nil
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-changes:
+			if updated, err := tournaments.TournamentByUUID(tID); err == nil {
+				tournament = updated
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+func canControlClock(c web.C, tournament *tournaments.Tournament) bool {
+	return c.Env["authIsAdmin"].(bool) || tournament.InField(c.Env["authPlayer"].(uuid.UUID))
+}
+
+func controlTournamentClock(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	action := c.URLParams["action"]
+	if action == "start" {
+		if !c.Env["authIsAdmin"].(bool) {
+			return &appError{errors.New("Unauthorized"), "Must be admin to start the clock", 403}
+		}
+		type StartRequest struct {
+			Field []uuid.UUID `json:"field"`
+		}
+		start := new(StartRequest)
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(start); err != nil {
+			return &appError{err, "Invalid JSON", 400}
+		}
+		err = tournament.StartClock(start.Field)
+	} else {
+		if !canControlClock(c, tournament) {
+			return &appError{errors.New("Unauthorized"), "Must be in the field or admin to control the clock", 403}
+		}
+		switch action {
+		case "pause":
+			err = tournament.PauseClock()
+		case "resume":
+			err = tournament.ResumeClock()
+		case "skip":
+			err = tournament.SkipLevel()
+		default:
+			return &appError{errors.New("Unknown action"), "Clock action must be start, pause, resume or skip", 404}
+		}
+	}
+	if err != nil {
+		return &appError{err, "Failed to " + action + " clock", 409}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournament.ClockState())
+	return nil
+}
+
+func resetTournamentClock(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to reset the clock", 403}
+	}
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	if err := tournament.ResetClock(); err != nil {
+		return &appError{err, "Failed to reset clock", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func addTournamentKnockout(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+	if !canControlClock(c, tournament) {
+		return &appError{errors.New("Unauthorized"), "Must be in the field or admin to register knockouts", 403}
+	}
+
+	// The last knockout sets the result, so debt options may be given
+	knockout := new(struct {
+		tournaments.Elimination
+		resultDebtOptions
+	})
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(knockout); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+	if aerr := knockout.resultDebtOptions.authorize(c); aerr != nil {
+		return aerr
+	}
+
+	err = tournament.RecordKnockout(knockout.Player, knockout.Hunter, c.Env["authPlayer"].(uuid.UUID), c.Env["authIsAdmin"].(bool))
+	if err != nil {
+		return &appError{err, "Failed to register knockout", 409}
+	}
+	// Results from others are submitted for confirmation, which
+	// generates the debts when confirmed
+	if !tournament.Clock.Finished.IsZero() && c.Env["authIsAdmin"].(bool) {
+		if err := generateResultDebts(tournament, knockout.resultDebtOptions); err != nil {
+			return &appError{err, "Failed to generate debts from tournament result", 500}
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournament.ClockState())
+	return nil
+}
+
+func removeTournamentKnockout(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+	if !canControlClock(c, tournament) {
+		return &appError{errors.New("Unauthorized"), "Must be in the field or admin to remove knockouts", 403}
+	}
+
+	pID, err := uuid.FromString(c.URLParams["playeruuid"])
+	if err := tournament.UndoKnockout(pID); err != nil {
+		return &appError{err, "Failed to remove knockout", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func getTournamentSeating(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+	if tournament.Seating == nil {
+		return &appError{errors.New("Not found"), "Seats are not drawn for tournament", 404}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournament.Seating)
+	return nil
+}
+
+func drawTournamentSeating(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if !c.Env["authIsAdmin"].(bool) {
+		return &appError{errors.New("Unauthorized"), "Must be admin to draw seats", 403}
+	}
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	type DrawRequest struct {
+		tournaments.SeatingConstraints
+		HostsAtTableOne bool `json:"hostsAtTableOne"`
+	}
+
+	draw := new(DrawRequest)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(draw); err != nil {
+		return &appError{err, "Invalid JSON", 400}
+	}
+
+	if draw.HostsAtTableOne && !tournament.Info.Location.IsZero() {
+		location, err := locations.LocationByUUID(tournament.Info.Location)
+		if err != nil {
+			return &appError{err, "Cant find tournament location", 500}
+		}
+		if draw.Fixed == nil {
+			draw.Fixed = make(map[uuid.UUID]int)
+		}
+		draw.Fixed[location.Host] = 1
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	if err := tournament.DrawSeating(draw.SeatingConstraints, rng); err != nil {
+		return &appError{err, "Failed to draw seats", 409}
+	}
+
+	w.WriteHeader(201)
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournament.Seating)
+	return nil
+}
+
+func getTournamentBalanceMoves(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(tournament.BalanceMoves())
+	return nil
+}
+
+func balanceTournamentTables(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+	if !canControlClock(c, tournament) {
+		return &appError{errors.New("Unauthorized"), "Must be in the field or admin to balance tables", 403}
+	}
+
+	moves, err := tournament.BalanceTables()
+	if err != nil {
+		return &appError{err, "Failed to balance tables", 409}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(moves)
+	return nil
+}
+
+func checkInTournamentPlayer(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	pID, err := uuid.FromString(c.URLParams["playeruuid"])
+	if !c.Env["authIsAdmin"].(bool) && pID != c.Env["authPlayer"].(uuid.UUID) {
+		return &appError{errors.New("Unauthorized"), "Must be given player or admin to check in", 403}
+	}
+
+	if err := tournament.CheckIn(pID); err != nil {
+		return &appError{err, "Failed to check in player", 409}
+	}
+	w.WriteHeader(204)
+	return nil
+}
+
+func checkOutTournamentPlayer(c web.C, w http.ResponseWriter, r *http.Request) *appError {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	tID, err := uuid.FromString(c.URLParams["uuid"])
+	tournament, err := tournaments.TournamentByUUID(tID)
+	if err != nil {
+		return &appError{err, "Cant find tournament", 404}
+	}
+
+	pID, err := uuid.FromString(c.URLParams["playeruuid"])
+	if !c.Env["authIsAdmin"].(bool) && pID != c.Env["authPlayer"].(uuid.UUID) {
+		return &appError{errors.New("Unauthorized"), "Must be given player or admin to check out", 403}
+	}
+
+	if err := tournament.CheckOut(pID); err != nil {
+		return &appError{err, "Failed to check out player", 409}
+	}
+	w.WriteHeader(204)
 	return nil
 }
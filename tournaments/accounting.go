@@ -0,0 +1,229 @@
+package tournaments
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/m4rw3r/uuid"
+)
+
+type EntryType int
+
+const (
+	BuyIn EntryType = iota
+	Rebuy
+	AddOn
+	Bounty
+)
+
+var EntryTypeNames = []string{
+	"buyin",
+	"rebuy",
+	"addon",
+	"bounty",
+}
+
+// An Entry is a single money flow in a tournament. Buy-ins, rebuys
+// and add-ons go into the prize pool, while bounties are paid directly
+// from the eliminated player (Victim) to the bounty hunter (Player).
+type Entry struct {
+	UUID    uuid.UUID `json:"uuid"`
+	Player  uuid.UUID `json:"player"`
+	Type    EntryType `json:"type"`
+	Amount  int       `json:"amount"`
+	Victim  uuid.UUID `json:"victim"`
+	Created time.Time `json:"created"`
+}
+
+type Accounting struct {
+	PrizePool int               `json:"prizePool"`
+	Paid      map[uuid.UUID]int `json:"paid"`
+	Received  map[uuid.UUID]int `json:"received"`
+	Net       map[uuid.UUID]int `json:"net"`
+}
+
+func validateEntry(e Entry) error {
+	if e.Player.IsZero() {
+		return errors.New("Entry needs a player")
+	}
+	if e.Amount <= 0 {
+		return errors.New("Entry needs a positive amount")
+	}
+	if e.Type < BuyIn || e.Type > Bounty {
+		return errors.New("Unknown entry type")
+	}
+	if e.Type == Bounty && (e.Victim.IsZero() || e.Victim == e.Player) {
+		return errors.New("Bounty entry needs a victim other than the player")
+	}
+	return nil
+}
+
+// Players that finished without a registered buy-in are assumed to
+// have paid the regular stake. This keeps tournaments registered before
+// entries were introduced giving the same numbers as before.
+func (t *Tournament) poolEntries() []Entry {
+	var entries []Entry
+	hasBuyIn := make(map[uuid.UUID]bool)
+	for _, e := range t.Entries {
+		if e.Type == BuyIn {
+			hasBuyIn[e.Player] = true
+		}
+		entries = append(entries, e)
+	}
+	for _, player := range t.FinishOrder() {
+		if !hasBuyIn[player] {
+			entries = append(entries, Entry{Player: player, Type: BuyIn, Amount: t.Info.Stake})
+		}
+	}
+	return entries
+}
+
+func (t *Tournament) PrizePool() int {
+	pool := 0
+	for _, e := range t.poolEntries() {
+		if e.Type != Bounty {
+			pool += e.Amount
+		}
+	}
+	return pool
+}
+
+// Compute the money flows of a tournament. The runner up gets the stake
+// back, and the winner takes the rest of the prize pool.
+func (t *Tournament) Accounting() *Accounting {
+	acc := &Accounting{
+		Paid:     make(map[uuid.UUID]int),
+		Received: make(map[uuid.UUID]int),
+		Net:      make(map[uuid.UUID]int),
+	}
+
+	for _, e := range t.poolEntries() {
+		if e.Type == Bounty {
+			acc.Paid[e.Victim] += e.Amount
+			acc.Received[e.Player] += e.Amount
+			continue
+		}
+		acc.PrizePool += e.Amount
+		acc.Paid[e.Player] += e.Amount
+	}
+
+	order := t.FinishOrder()
+	if len(order) > 1 {
+		runnerUp := t.Info.Stake
+		if runnerUp > acc.PrizePool {
+			runnerUp = acc.PrizePool
+		}
+		acc.Received[order[1]] += runnerUp
+		acc.Received[order[0]] += acc.PrizePool - runnerUp
+	} else if len(order) == 1 {
+		acc.Received[order[0]] += acc.PrizePool
+	}
+
+	for player, amount := range acc.Paid {
+		acc.Net[player] -= amount
+	}
+	for player, amount := range acc.Received {
+		acc.Net[player] += amount
+	}
+	return acc
+}
+
+// A debt following from the result of a tournament
+type ResultDebt struct {
+	Debitor  uuid.UUID `json:"debitor"`
+	Creditor uuid.UUID `json:"creditor"`
+	Amount   int       `json:"amount"`
+	Bounty   bool      `json:"bounty"`
+}
+
+// The debts the players owe each other after the tournament. Players
+// losing money in the prize pool pay the players winning from it in the
+// order they finished, normally leaving the winner as the only creditor,
+// while bounties are owed directly by the victim to the hunter.
+func (t *Tournament) ResultDebts() []ResultDebt {
+	debts := []ResultDebt{}
+	if len(t.Result) == 0 {
+		return debts
+	}
+
+	pool := make(map[uuid.UUID]int)
+	for _, e := range t.poolEntries() {
+		if e.Type == Bounty {
+			debts = append(debts, ResultDebt{Debitor: e.Victim, Creditor: e.Player, Amount: e.Amount, Bounty: true})
+			continue
+		}
+		pool[e.Player] -= e.Amount
+	}
+	acc := t.Accounting()
+	for player, amount := range acc.Received {
+		pool[player] += amount
+	}
+	// Bounties were already counted as received
+	for _, d := range debts {
+		pool[d.Creditor] -= d.Amount
+	}
+
+	// Entries from players not placed are paid after everyone placed
+	order := t.FinishOrder()
+	var others []uuid.UUID
+	for player := range pool {
+		if placeIn(order, player) == 0 {
+			others = append(others, player)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].String() < others[j].String() })
+	order = append(order, others...)
+
+	for _, debitor := range order {
+		for _, creditor := range order {
+			if pool[debitor] >= 0 {
+				break
+			}
+			if pool[creditor] <= 0 {
+				continue
+			}
+			amount := -pool[debitor]
+			if pool[creditor] < amount {
+				amount = pool[creditor]
+			}
+			debts = append(debts, ResultDebt{Debitor: debitor, Creditor: creditor, Amount: amount})
+			pool[debitor] += amount
+			pool[creditor] -= amount
+		}
+	}
+	return debts
+}
+
+func (t *Tournament) AddEntry(e Entry) error {
+	if err := validateEntry(e); err != nil {
+		return errors.New(err.Error() + " - Could not add tournament entry")
+	}
+	e.UUID, _ = uuid.V4()
+	if e.Created.IsZero() {
+		e.Created = time.Now()
+	}
+	t.Entries = append(t.Entries, e)
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store tournament with added entry")
+	}
+	return nil
+}
+
+func (t *Tournament) RemoveEntry(entry uuid.UUID) error {
+	found := false
+	for i, e := range t.Entries {
+		if e.UUID == entry {
+			t.Entries = append(t.Entries[:i], t.Entries[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.New("Entry not found")
+	}
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store tournament with removed entry")
+	}
+	return nil
+}
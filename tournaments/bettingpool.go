@@ -0,0 +1,243 @@
+package tournaments
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/m4rw3r/uuid"
+)
+
+// Points given when scoring a prediction against the actual result
+type BetScoring struct {
+	Exact    int `json:"exact"`
+	OffByOne int `json:"offByOne"`
+	Winner   int `json:"winner"`
+	Loser    int `json:"loser"`
+}
+
+var DefaultBetScoring = BetScoring{Exact: 3, OffByOne: 1, Winner: 5, Loser: 2}
+
+type BetScore struct {
+	Player   uuid.UUID `json:"player"`
+	Points   int       `json:"points"`
+	NumExact int       `json:"exact"`
+}
+
+type BetScores []*BetScore
+
+func (s BetScores) Len() int      { return len(s) }
+func (s BetScores) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s BetScores) Less(i, j int) bool {
+	if s[i].Points == s[j].Points {
+		return s[i].NumExact > s[j].NumExact
+	}
+	return s[i].Points > s[j].Points
+}
+
+type BetStanding struct {
+	Player   uuid.UUID `json:"player"`
+	Points   int       `json:"points"`
+	NumExact int       `json:"exact"`
+	NumBets  int       `json:"bets"`
+	NumWins  int       `json:"wins"`
+}
+
+type BetStandings []*BetStanding
+
+func (s BetStandings) Len() int      { return len(s) }
+func (s BetStandings) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s BetStandings) Less(i, j int) bool {
+	if s[i].Points == s[j].Points {
+		if s[i].NumWins == s[j].NumWins {
+			return s[i].NumExact > s[j].NumExact
+		}
+		return s[i].NumWins > s[j].NumWins
+	}
+	return s[i].Points > s[j].Points
+}
+
+func validatePrediction(prediction Result) error {
+	if len(prediction) == 0 {
+		return errors.New("Prediction can not be empty")
+	}
+	seen := make(map[uuid.UUID]bool)
+	for _, player := range prediction {
+		if seen[player] {
+			return errors.New("Prediction contains the same player more than once")
+		}
+		seen[player] = true
+	}
+	return nil
+}
+
+// Bets are locked once the tournament is scheduled to start
+func (t *Tournament) BetsLocked() bool {
+	return t.Played || !time.Now().Before(t.Info.Scheduled)
+}
+
+func (t *Tournament) BetByPlayer(player uuid.UUID) (*Bet, error) {
+	for i := range t.Bets {
+		if t.Bets[i].Player == player {
+			return &t.Bets[i], nil
+		}
+	}
+	return nil, errors.New("Bet not found")
+}
+
+func (t *Tournament) PlaceBet(player uuid.UUID, prediction Result) error {
+	if t.BetsLocked() {
+		return errors.New("Betting pool is locked")
+	}
+	if err := validatePrediction(prediction); err != nil {
+		return errors.New(err.Error() + " - Could not place bet")
+	}
+
+	if bet, err := t.BetByPlayer(player); err == nil {
+		bet.Prediction = prediction
+		bet.Updated = time.Now()
+	} else {
+		now := time.Now()
+		t.Bets = append(t.Bets, Bet{Player: player, Prediction: prediction, Placed: now, Updated: now})
+	}
+
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store tournament with placed bet")
+	}
+	return nil
+}
+
+func (t *Tournament) WithdrawBet(player uuid.UUID) error {
+	if t.BetsLocked() {
+		return errors.New("Betting pool is locked")
+	}
+	found := false
+	for i, b := range t.Bets {
+		if b.Player == player {
+			t.Bets = append(t.Bets[:i], t.Bets[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.New("Bet not found")
+	}
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store tournament with withdrawn bet")
+	}
+	return nil
+}
+
+func (b *Bet) Score(result Result, scoring BetScoring) *BetScore {
+	score := &BetScore{Player: b.Player}
+
+	actual := make(map[uuid.UUID]int)
+	for i, player := range result {
+		actual[player] = i
+	}
+
+	for i, player := range b.Prediction {
+		place, found := actual[player]
+		if !found {
+			continue
+		}
+		switch place - i {
+		case 0:
+			score.Points += scoring.Exact
+			score.NumExact += 1
+		case -1, 1:
+			score.Points += scoring.OffByOne
+		}
+	}
+
+	// Empty predictions and results have neither winner nor loser
+	if len(b.Prediction) == 0 || len(result) == 0 {
+		return score
+	}
+	if b.Prediction[0] == result[0] {
+		score.Points += scoring.Winner
+	}
+	if b.Prediction[len(b.Prediction)-1] == result[len(result)-1] {
+		score.Points += scoring.Loser
+	}
+	return score
+}
+
+func (t *Tournament) ScoreBets(scoring BetScoring) BetScores {
+	var scores BetScores
+	if !t.Played {
+		return scores
+	}
+	for i := range t.Bets {
+		scores = append(scores, t.Bets[i].Score(t.FinishOrder(), scoring))
+	}
+	sort.Stable(scores)
+	return scores
+}
+
+func BettingPoolStandings(tournaments Tournaments, scoring BetScoring) BetStandings {
+	byPlayer := make(map[uuid.UUID]*BetStanding)
+	var standings BetStandings
+
+	for _, t := range tournaments {
+		scores := t.ScoreBets(scoring)
+		for i, s := range scores {
+			bs, found := byPlayer[s.Player]
+			if !found {
+				bs = &BetStanding{Player: s.Player}
+				byPlayer[s.Player] = bs
+				standings = append(standings, bs)
+			}
+			bs.Points += s.Points
+			bs.NumExact += s.NumExact
+			bs.NumBets += 1
+			if i == 0 || (s.Points == scores[0].Points && s.NumExact == scores[0].NumExact) {
+				bs.NumWins += 1
+			}
+		}
+	}
+
+	sort.Stable(standings)
+	return standings
+}
+
+func validateBetScoring(scoring BetScoring) error {
+	if scoring.Exact < 0 || scoring.OffByOne < 0 || scoring.Winner < 0 || scoring.Loser < 0 {
+		return errors.New("Bet scoring points can not be negative")
+	}
+	return nil
+}
+
+func SetSeasonBetScoring(season int, scoring BetScoring) error {
+	if err := validateBetScoring(scoring); err != nil {
+		return errors.New(err.Error() + " - Could not set bet scoring")
+	}
+	if err := storage.StoreBetScoring(season, &scoring); err != nil {
+		return errors.New(err.Error() + " - Could not write bet scoring to storage")
+	}
+	return nil
+}
+
+// The bet scoring of the season, or the default scoring if none is set
+func SeasonBetScoring(season int) (BetScoring, error) {
+	scoring, err := storage.LoadBetScoring(season)
+	if err != nil {
+		return BetScoring{}, errors.New(err.Error() + " - Could not load bet scoring")
+	}
+	if scoring == nil {
+		return DefaultBetScoring, nil
+	}
+	return *scoring, nil
+}
+
+func SeasonBettingPool(season int) (BetStandings, error) {
+	scoring, err := SeasonBetScoring(season)
+	if err != nil {
+		return nil, err
+	}
+	tList, err := TournamentsBySeason(season)
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not load tournaments for betting pool")
+	}
+	return BettingPoolStandings(tList, scoring), nil
+}
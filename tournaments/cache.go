@@ -0,0 +1,207 @@
+package tournaments
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m4rw3r/uuid"
+)
+
+// Standings and stats of a single season, computed once and kept until
+// a tournament in the season is stored again
+type seasonData struct {
+	tournaments Tournaments
+	standings   PlayerStandings
+	yellows     []YellowPeriod
+	monthStats  []*MonthStats
+	progression Progression
+	computed    time.Time
+	duration    time.Duration
+}
+
+// Generations are bumped on every invalidation, so that stats computed
+// while a tournament was being stored are not cached
+var seasonCache = struct {
+	sync.Mutex
+	m           map[int]*seasonData
+	generations map[int]int
+}{m: make(map[int]*seasonData), generations: make(map[int]int)}
+
+// Ratings carry over between seasons, so they are computed over all
+// tournaments and invalidated together with any season
+var ratingsCache = struct {
+	sync.Mutex
+	ratings    map[uuid.UUID]float64
+	generation int
+}{}
+
+func invalidateSeason(season int) {
+	seasonCache.Lock()
+	delete(seasonCache.m, season)
+	seasonCache.generations[season] += 1
+	seasonCache.Unlock()
+
+	ratingsCache.Lock()
+	ratingsCache.ratings = nil
+	ratingsCache.generation += 1
+	ratingsCache.Unlock()
+}
+
+// The current rating of every player. The map is shared, and must not
+// be modified.
+func cachedRatings() map[uuid.UUID]float64 {
+	ratingsCache.Lock()
+	ratings := ratingsCache.ratings
+	generation := ratingsCache.generation
+	ratingsCache.Unlock()
+	if ratings != nil {
+		return ratings
+	}
+
+	all, err := AllTournaments()
+	if err != nil {
+		return map[uuid.UUID]float64{}
+	}
+	ratings, _ = Ratings(all)
+
+	ratingsCache.Lock()
+	if ratingsCache.generation == generation {
+		ratingsCache.ratings = ratings
+	}
+	ratingsCache.Unlock()
+	return ratings
+}
+
+func cachedSeason(season int) (*seasonData, error) {
+	seasonCache.Lock()
+	data, found := seasonCache.m[season]
+	generation := seasonCache.generations[season]
+	seasonCache.Unlock()
+	if found {
+		return data, nil
+	}
+
+	start := time.Now()
+	tList, err := TournamentsBySeason(season)
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not load tournaments for season stats")
+	}
+	sort.Stable(tList)
+
+	stats, err := NewSeasonStats(tList, season)
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not compute season stats")
+	}
+	progression, err := NewProgression(tList)
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not compute season progression")
+	}
+	data = &seasonData{
+		tournaments: tList,
+		standings:   NewStandings(tList, nil),
+		yellows:     stats.YellowPeriods,
+		monthStats:  stats.MonthStats,
+		progression: progression,
+	}
+	data.computed = time.Now()
+	data.duration = data.computed.Sub(start)
+
+	seasonCache.Lock()
+	if seasonCache.generations[season] == generation {
+		seasonCache.m[season] = data
+	}
+	seasonCache.Unlock()
+	return data, nil
+}
+
+// Copy standings so callers can sort and modify them without touching the cache
+func copyStandings(standings PlayerStandings) PlayerStandings {
+	copied := make(PlayerStandings, len(standings))
+	for i, ps := range standings {
+		c := *ps
+		c.Results = append(PlayerResults{}, ps.Results...)
+		copied[i] = &c
+	}
+	return copied
+}
+
+// Season standings with the current ratings of the players, which are
+// kept apart as they change with tournaments in any season
+func cachedStandings(season int) (PlayerStandings, error) {
+	data, err := cachedSeason(season)
+	if err != nil {
+		return nil, err
+	}
+	standings := copyStandings(data.standings)
+	ratings := cachedRatings()
+	for _, ps := range standings {
+		ps.Rating = ratings[ps.Player]
+	}
+	return standings, nil
+}
+
+// Join yellow periods of consecutive seasons. A period continues into
+// the next season when the leader stays the same.
+func joinYellowPeriods(periods []YellowPeriod, next []YellowPeriod) []YellowPeriod {
+	if len(periods) == 0 {
+		return append(periods, next...)
+	}
+	if len(next) == 0 {
+		return periods
+	}
+	last := &periods[len(periods)-1]
+	first := next[0]
+	if last.Player == first.Player {
+		last.To = first.To
+		last.Active = first.Active
+		return append(periods, next[1:]...)
+	}
+	last.To = first.From
+	last.Active = false
+	return append(periods, next...)
+}
+
+// When the cached stats for the given seasons were computed, and the
+// total time spent computing them
+func CacheInfo(seasons []int) (time.Time, time.Duration, error) {
+	var computed time.Time
+	var duration time.Duration
+	for _, season := range seasons {
+		data, err := cachedSeason(season)
+		if err != nil {
+			return computed, duration, err
+		}
+		if data.computed.After(computed) {
+			computed = data.computed
+		}
+		duration += data.duration
+	}
+	return computed, duration, nil
+}
+
+//
+// Storage wrapper keeping the cache up to date with stored tournaments
+//
+
+type cachingStorage struct {
+	TournamentStorage
+}
+
+func (cs *cachingStorage) Store(t *Tournament) error {
+	// The tournament may have been moved from another season
+	if old, err := cs.TournamentStorage.Load(t.UUID); err == nil {
+		invalidateSeason(old.Info.Season)
+	}
+	err := cs.TournamentStorage.Store(t)
+	invalidateSeason(t.Info.Season)
+	return err
+}
+
+func (cs *cachingStorage) Delete(uuid uuid.UUID) error {
+	if old, err := cs.TournamentStorage.Load(uuid); err == nil {
+		invalidateSeason(old.Info.Season)
+	}
+	return cs.TournamentStorage.Delete(uuid)
+}
@@ -0,0 +1,200 @@
+package tournaments
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/m4rw3r/uuid"
+)
+
+type Milestone struct {
+	Name       string    `json:"name"`
+	When       time.Time `json:"when"`
+	Tournament uuid.UUID `json:"tournament"`
+}
+
+type Award struct {
+	Season int    `json:"season"`
+	Title  string `json:"title"`
+}
+
+type CareerSeason struct {
+	Season      int             `json:"season"`
+	Standing    *PlayerStanding `json:"standing"`
+	Rank        int             `json:"rank"`
+	YellowDays  int             `json:"yellowDays"`
+	BestMonths  []time.Month    `json:"bestMonths"`
+	WorstMonths []time.Month    `json:"worstMonths"`
+	Titles      []string        `json:"titles"`
+}
+
+type Career struct {
+	Player      uuid.UUID       `json:"player"`
+	Total       *PlayerStanding `json:"total"`
+	Seasons     []*CareerSeason `json:"seasons"`
+	Titles      []Award         `json:"titles"`
+	BestMonths  int             `json:"bestMonths"`
+	WorstMonths int             `json:"worstMonths"`
+	YellowDays  int             `json:"yellowDays"`
+	Milestones  []Milestone     `json:"milestones"`
+}
+
+var tournamentMilestones = []int{10, 25, 50, 100, 150, 200, 250, 300}
+var winMilestones = []int{5, 10, 25, 50, 75, 100}
+
+func careerMilestones(player uuid.UUID, tournaments Tournaments) []Milestone {
+	var milestones []Milestone
+	numPlayed, numWins, numHeadsUp, numKnockouts := 0, 0, 0, 0
+
+	for _, t := range chronological(tournaments) {
+		place := placeIn(t.FinishOrder(), player)
+		if place == 0 {
+			continue
+		}
+		add := func(name string) {
+			milestones = append(milestones, Milestone{Name: name, When: t.Info.Scheduled, Tournament: t.UUID})
+		}
+
+		numPlayed += 1
+		if numPlayed == 1 {
+			add("Første turnering")
+		}
+		for _, n := range tournamentMilestones {
+			if numPlayed == n {
+				add(fmt.Sprintf("%d. turnering", n))
+			}
+		}
+
+		if place <= 2 {
+			numHeadsUp += 1
+			if numHeadsUp == 1 {
+				add("Første heads up")
+			}
+		}
+
+		if place == 1 {
+			numWins += 1
+			if numWins == 1 {
+				add("Første seier")
+			}
+			for _, n := range winMilestones {
+				if numWins == n {
+					add(fmt.Sprintf("%d. seier", n))
+				}
+			}
+		}
+
+		if len(t.BountyHunters[player]) > 0 {
+			if numKnockouts == 0 {
+				add("Første knockout")
+			}
+			numKnockouts += len(t.BountyHunters[player])
+		}
+	}
+	return milestones
+}
+
+func seasonTitleNames(titles *SeasonTitles, player uuid.UUID) []string {
+	var names []string
+	if titles.Champion.Uuid == player {
+		names = append(names, "champion")
+	}
+	if titles.AvgPlaceWinner.Uuid == player {
+		names = append(names, "avgPlaceWinner")
+	}
+	if titles.PointsWinner.Uuid == player {
+		names = append(names, "pointsWinner")
+	}
+	if titles.MostYellowDays.Uuid == player {
+		names = append(names, "mostYellowDays")
+	}
+	if titles.PlayerOfTheYear.Uuid == player {
+		names = append(names, "playerOfTheYear")
+	}
+	if titles.LoserOfTheYear.Uuid == player {
+		names = append(names, "loserOfTheYear")
+	}
+	if titles.BountyWinner.Uuid == player {
+		names = append(names, "bountyWinner")
+	}
+	return names
+}
+
+// Aggregate everything a player has achieved, per season and in total
+func PlayerCareer(player uuid.UUID) (*Career, error) {
+	all, err := AllTournaments()
+	if err != nil {
+		return nil, err
+	}
+	seasons := all.Seasons()
+	sort.Ints(seasons)
+
+	career := &Career{Player: player}
+	stats, err := SeasonStats(seasons)
+	if err != nil {
+		return nil, err
+	}
+	titleList, err := Titles(seasons)
+	if err != nil {
+		return nil, err
+	}
+
+	var total PlayerStandings
+	for _, season := range seasons {
+		standings, err := cachedStandings(season)
+		if err != nil {
+			return nil, err
+		}
+		standings.ByWinnings(season < 2013)
+
+		cs := &CareerSeason{Season: season}
+		for i, ps := range standings {
+			if ps.Player == player {
+				cs.Standing = ps
+				cs.Rank = i + 1
+			}
+		}
+		if cs.Standing == nil {
+			continue
+		}
+		total = total.Combine(PlayerStandings{cs.Standing})
+
+		cs.YellowDays = YellowDaysInSeason(stats.YellowPeriods, season)[player]
+		career.YellowDays += cs.YellowDays
+
+		for _, ms := range stats.MonthStats {
+			if ms.Year != season {
+				continue
+			}
+			if ms.Best == player {
+				cs.BestMonths = append(cs.BestMonths, ms.Month)
+				career.BestMonths += 1
+			}
+			if ms.Worst == player {
+				cs.WorstMonths = append(cs.WorstMonths, ms.Month)
+				career.WorstMonths += 1
+			}
+		}
+
+		for _, titles := range titleList {
+			if titles.Season != season {
+				continue
+			}
+			cs.Titles = seasonTitleNames(titles, player)
+			for _, name := range cs.Titles {
+				career.Titles = append(career.Titles, Award{Season: season, Title: name})
+			}
+		}
+		career.Seasons = append(career.Seasons, cs)
+	}
+
+	if len(total) > 0 {
+		combined := *total[0]
+		career.Total = &combined
+		ratings := cachedRatings()
+		career.Total.Rating = ratings[player]
+	}
+	career.Milestones = careerMilestones(player, all)
+	return career, nil
+}
@@ -0,0 +1,354 @@
+package tournaments
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m4rw3r/uuid"
+)
+
+type BlindLevel struct {
+	SmallBlind int  `json:"smallBlind"`
+	BigBlind   int  `json:"bigBlind"`
+	Ante       int  `json:"ante"`
+	Minutes    int  `json:"minutes"`
+	Break      bool `json:"break"`
+}
+
+// The server side clock of a tournament in progress. Elapsed is time
+// spent in the current level before LevelStarted, i.e. before the
+// latest resume.
+type Clock struct {
+	Field        []uuid.UUID   `json:"field"`
+	Level        int           `json:"level"`
+	LevelStarted time.Time     `json:"levelStarted"`
+	Elapsed      time.Duration `json:"elapsed"`
+	Running      bool          `json:"running"`
+	Started      time.Time     `json:"started"`
+	Finished     time.Time     `json:"finished"`
+}
+
+type Elimination struct {
+	Player uuid.UUID `json:"player"`
+	Hunter uuid.UUID `json:"hunter"`
+	When   time.Time `json:"when"`
+	Level  int       `json:"level"`
+}
+
+type ClockState struct {
+	Tournament  uuid.UUID   `json:"tournament"`
+	Running     bool        `json:"running"`
+	Finished    bool        `json:"finished"`
+	Level       int         `json:"level"`
+	Current     *BlindLevel `json:"current"`
+	Next        *BlindLevel `json:"next"`
+	Remaining   int         `json:"remaining"`
+	PlayersLeft int         `json:"playersLeft"`
+}
+
+func validateBlinds(blinds []BlindLevel) error {
+	if len(blinds) == 0 {
+		return errors.New("Blind structure needs at least one level")
+	}
+	for _, l := range blinds {
+		if l.Minutes <= 0 {
+			return errors.New("Blind levels needs a positive duration")
+		}
+		if !l.Break && (l.SmallBlind <= 0 || l.BigBlind < l.SmallBlind) {
+			return errors.New("Blind levels needs valid blinds")
+		}
+	}
+	return nil
+}
+
+func levelDuration(l BlindLevel) time.Duration {
+	return time.Duration(l.Minutes) * time.Minute
+}
+
+// Move the clock forward to the given time, rolling over to the next
+// levels as their time runs out. The last level never runs out.
+func (c *Clock) advance(blinds []BlindLevel, now time.Time) {
+	if !c.Running {
+		return
+	}
+	for c.Level < len(blinds)-1 {
+		left := levelDuration(blinds[c.Level]) - c.Elapsed
+		if now.Sub(c.LevelStarted) < left {
+			return
+		}
+		c.LevelStarted = c.LevelStarted.Add(left)
+		c.Elapsed = 0
+		c.Level += 1
+	}
+}
+
+func (t *Tournament) eliminated(player uuid.UUID) bool {
+	for _, e := range t.Eliminations {
+		if e.Player == player {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tournament) InField(player uuid.UUID) bool {
+	if t.Clock == nil {
+		return false
+	}
+	for _, p := range t.Clock.Field {
+		if p == player {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tournament) PlayersLeft() []uuid.UUID {
+	var left []uuid.UUID
+	if t.Clock == nil {
+		return left
+	}
+	for _, p := range t.Clock.Field {
+		if !t.eliminated(p) {
+			left = append(left, p)
+		}
+	}
+	return left
+}
+
+func (t *Tournament) ClockState() *ClockState {
+	state := &ClockState{Tournament: t.UUID}
+	if t.Clock == nil || len(t.Blinds) == 0 {
+		return state
+	}
+
+	c := *t.Clock
+	now := time.Now()
+	c.advance(t.Blinds, now)
+
+	spent := c.Elapsed
+	if c.Running {
+		spent += now.Sub(c.LevelStarted)
+	}
+	remaining := levelDuration(t.Blinds[c.Level]) - spent
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	state.Running = c.Running
+	state.Finished = !c.Finished.IsZero()
+	state.Level = c.Level
+	state.Current = &t.Blinds[c.Level]
+	if c.Level+1 < len(t.Blinds) {
+		state.Next = &t.Blinds[c.Level+1]
+	}
+	state.Remaining = int(remaining.Seconds())
+	state.PlayersLeft = len(t.PlayersLeft())
+	return state
+}
+
+func (t *Tournament) storeClock(errMsg string) error {
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - " + errMsg)
+	}
+	notifyClockWatchers(t.UUID)
+	return nil
+}
+
+func (t *Tournament) SetBlinds(blinds []BlindLevel) error {
+	if err := validateBlinds(blinds); err != nil {
+		return errors.New(err.Error() + " - Could not set blind structure")
+	}
+	if t.Clock != nil && t.Clock.Running {
+		return errors.New("Can not change blind structure while the clock is running")
+	}
+	t.Blinds = blinds
+	return t.storeClock("Could not store tournament blind structure")
+}
+
+func (t *Tournament) StartClock(field []uuid.UUID) error {
+	if t.Played {
+		return errors.New("Tournament is already played")
+	}
+	if len(t.Blinds) == 0 {
+		return errors.New("Tournament has no blind structure")
+	}
+	if t.Clock != nil {
+		return errors.New("Clock is already started")
+	}
+	seen := make(map[uuid.UUID]bool)
+	for _, p := range field {
+		if seen[p] {
+			return errors.New("Field contains the same player more than once")
+		}
+		seen[p] = true
+	}
+	if len(field) < 2 {
+		return errors.New("Need at least two players to start the clock")
+	}
+
+	now := time.Now()
+	t.Clock = &Clock{
+		Field:        field,
+		LevelStarted: now,
+		Running:      true,
+		Started:      now,
+	}
+	t.Eliminations = nil
+	return t.storeClock("Could not store started tournament clock")
+}
+
+func (t *Tournament) PauseClock() error {
+	if t.Clock == nil || !t.Clock.Running {
+		return errors.New("Clock is not running")
+	}
+	now := time.Now()
+	t.Clock.advance(t.Blinds, now)
+	t.Clock.Elapsed += now.Sub(t.Clock.LevelStarted)
+	t.Clock.Running = false
+	return t.storeClock("Could not store paused tournament clock")
+}
+
+func (t *Tournament) ResumeClock() error {
+	if t.Clock == nil || t.Clock.Running || !t.Clock.Finished.IsZero() {
+		return errors.New("Clock is not paused")
+	}
+	t.Clock.LevelStarted = time.Now()
+	t.Clock.Running = true
+	return t.storeClock("Could not store resumed tournament clock")
+}
+
+func (t *Tournament) SkipLevel() error {
+	if t.Clock == nil || !t.Clock.Finished.IsZero() {
+		return errors.New("Clock is not started")
+	}
+	now := time.Now()
+	t.Clock.advance(t.Blinds, now)
+	if t.Clock.Level >= len(t.Blinds)-1 {
+		return errors.New("Already at the last level")
+	}
+	t.Clock.Level += 1
+	t.Clock.LevelStarted = now
+	t.Clock.Elapsed = 0
+	return t.storeClock("Could not store tournament clock with skipped level")
+}
+
+// Record that player was knocked out by hunter. When only one player
+// is left, the result and bounty hunters are set in elimination order.
+// The result is only set directly when recorded by an admin, and is
+// otherwise submitted for confirmation like other results.
+func (t *Tournament) RecordKnockout(player uuid.UUID, hunter uuid.UUID, by uuid.UUID, isAdmin bool) error {
+	if t.Clock == nil || !t.Clock.Finished.IsZero() {
+		return errors.New("Clock is not started")
+	}
+	if !t.InField(player) || t.eliminated(player) {
+		return errors.New("Player is not left in the tournament")
+	}
+	if !hunter.IsZero() && (hunter == player || !t.InField(hunter) || t.eliminated(hunter)) {
+		return errors.New("Bounty hunter is not left in the tournament")
+	}
+
+	now := time.Now()
+	t.Clock.advance(t.Blinds, now)
+	t.Eliminations = append(t.Eliminations, Elimination{
+		Player: player,
+		Hunter: hunter,
+		When:   now,
+		Level:  t.Clock.Level,
+	})
+
+	left := t.PlayersLeft()
+	if len(left) > 1 {
+		return t.storeClock("Could not store tournament with knockout")
+	}
+
+	bh := make(BountyHunters)
+	result := Result{left[0]}
+	for i := len(t.Eliminations) - 1; i >= 0; i-- {
+		e := t.Eliminations[i]
+		result = append(result, e.Player)
+		if !e.Hunter.IsZero() {
+			bh[e.Hunter] = append(bh[e.Hunter], e.Player)
+		}
+	}
+
+	t.Clock.Running = false
+	t.Clock.Finished = now
+	if !isAdmin {
+		if err := t.SubmitResult(result, bh, by, nil); err != nil {
+			return err
+		}
+		notifyClockWatchers(t.UUID)
+		return nil
+	}
+	t.BountyHunters = bh
+	if err := t.SetResult(result, nil); err != nil {
+		return err
+	}
+	notifyClockWatchers(t.UUID)
+	return nil
+}
+
+// Undo the last knockout, e.g. when registered by mistake
+func (t *Tournament) UndoKnockout(player uuid.UUID) error {
+	if t.Clock == nil || !t.Clock.Finished.IsZero() {
+		return errors.New("Clock is not started")
+	}
+	n := len(t.Eliminations)
+	if n == 0 || t.Eliminations[n-1].Player != player {
+		return errors.New("Only the last knockout can be undone")
+	}
+	t.Eliminations = t.Eliminations[:n-1]
+	return t.storeClock("Could not store tournament with removed knockout")
+}
+
+// Throw away the clock and any knockouts, e.g. if started with the wrong field
+func (t *Tournament) ResetClock() error {
+	if t.Played {
+		return errors.New("Tournament is already played")
+	}
+	t.Clock = nil
+	t.Eliminations = nil
+	return t.storeClock("Could not store tournament with reset clock")
+}
+
+//
+// Clients watching a tournament clock are notified on every change
+//
+
+var clockWatchers = struct {
+	sync.Mutex
+	m map[uuid.UUID]map[chan bool]bool
+}{m: make(map[uuid.UUID]map[chan bool]bool)}
+
+// Watch the clock of a tournament. Call the returned function when done.
+func WatchClock(tournament uuid.UUID) (<-chan bool, func()) {
+	ch := make(chan bool, 1)
+	clockWatchers.Lock()
+	if clockWatchers.m[tournament] == nil {
+		clockWatchers.m[tournament] = make(map[chan bool]bool)
+	}
+	clockWatchers.m[tournament][ch] = true
+	clockWatchers.Unlock()
+
+	return ch, func() {
+		clockWatchers.Lock()
+		delete(clockWatchers.m[tournament], ch)
+		if len(clockWatchers.m[tournament]) == 0 {
+			delete(clockWatchers.m, tournament)
+		}
+		clockWatchers.Unlock()
+	}
+}
+
+func notifyClockWatchers(tournament uuid.UUID) {
+	clockWatchers.Lock()
+	defer clockWatchers.Unlock()
+	for ch := range clockWatchers.m[tournament] {
+		select {
+		case ch <- true:
+		default:
+		}
+	}
+}
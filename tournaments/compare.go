@@ -0,0 +1,94 @@
+package tournaments
+
+import (
+	"github.com/m4rw3r/uuid"
+)
+
+// The record of a player against another player, counted only in
+// tournaments where both players are in the result
+type HeadToHeadRecord struct {
+	Tournaments   int `json:"tournaments"`
+	Ahead         int `json:"ahead"`
+	Behind        int `json:"behind"`
+	KnockedOut    int `json:"knockedOut"`
+	KnockedOutBy  int `json:"knockedOutBy"`
+	HeadsUp       int `json:"headsUp"`
+	HeadsUpWins   int `json:"headsUpWins"`
+	Winnings      int `json:"winnings"`
+	OtherWinnings int `json:"otherWinnings"`
+	MoneyDiff     int `json:"moneyDiff"`
+}
+
+type HeadToHead struct {
+	Player   uuid.UUID                 `json:"player"`
+	Other    uuid.UUID                 `json:"other"`
+	Total    *HeadToHeadRecord         `json:"total"`
+	BySeason map[int]*HeadToHeadRecord `json:"bySeason"`
+}
+
+func placeIn(result Result, player uuid.UUID) int {
+	for i, p := range result {
+		if p == player {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func countKnockouts(bh BountyHunters, hunter, victim uuid.UUID) int {
+	count := 0
+	for _, v := range bh[hunter] {
+		if v == victim {
+			count += 1
+		}
+	}
+	return count
+}
+
+func (r *HeadToHeadRecord) add(t *Tournament, player, other uuid.UUID) {
+	order := t.FinishOrder()
+	place, otherPlace := placeIn(order, player), placeIn(order, other)
+	net := t.Accounting().Net
+
+	r.Tournaments += 1
+	if place < otherPlace {
+		r.Ahead += 1
+	} else {
+		r.Behind += 1
+	}
+	r.KnockedOut += countKnockouts(t.BountyHunters, player, other)
+	r.KnockedOutBy += countKnockouts(t.BountyHunters, other, player)
+	if place <= 2 && otherPlace <= 2 {
+		r.HeadsUp += 1
+		if place == 1 {
+			r.HeadsUpWins += 1
+		}
+	}
+	r.Winnings += net[player]
+	r.OtherWinnings += net[other]
+	r.MoneyDiff = r.Winnings - r.OtherWinnings
+}
+
+func Compare(player, other uuid.UUID, tournaments Tournaments) *HeadToHead {
+	h2h := &HeadToHead{
+		Player:   player,
+		Other:    other,
+		Total:    new(HeadToHeadRecord),
+		BySeason: make(map[int]*HeadToHeadRecord),
+	}
+
+	for _, t := range tournaments {
+		order := t.FinishOrder()
+		if !t.Played || placeIn(order, player) == 0 || placeIn(order, other) == 0 {
+			continue
+		}
+		season, found := h2h.BySeason[t.Info.Season]
+		if !found {
+			season = new(HeadToHeadRecord)
+			h2h.BySeason[t.Info.Season] = season
+		}
+		season.add(t, player, other)
+		h2h.Total.add(t, player, other)
+	}
+	return h2h
+}
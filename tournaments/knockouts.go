@@ -0,0 +1,160 @@
+package tournaments
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/m4rw3r/uuid"
+)
+
+type KnockoutNode struct {
+	Player     uuid.UUID `json:"uuid"`
+	Knockouts  int       `json:"knockouts"`
+	KnockedOut int       `json:"knockedOut"`
+}
+
+// The number of times Hunter knocked out Victim, in total and by season
+type KnockoutEdge struct {
+	Hunter   uuid.UUID   `json:"hunter"`
+	Victim   uuid.UUID   `json:"victim"`
+	Count    int         `json:"count"`
+	BySeason map[int]int `json:"bySeason"`
+}
+
+type KnockoutGraph struct {
+	Nodes []*KnockoutNode `json:"nodes"`
+	Edges []*KnockoutEdge `json:"edges"`
+}
+
+type SeasonKnockouts struct {
+	Knockouts  int `json:"knockouts"`
+	KnockedOut int `json:"knockedOut"`
+}
+
+type PlayerKnockouts struct {
+	Player          uuid.UUID                `json:"uuid"`
+	Knockouts       int                      `json:"knockouts"`
+	KnockedOut      int                      `json:"knockedOut"`
+	Nemesis         *KnockoutEdge            `json:"nemesis"`
+	FavouriteVictim *KnockoutEdge            `json:"favouriteVictim"`
+	BySeason        map[int]*SeasonKnockouts `json:"bySeason"`
+	Edges           []*KnockoutEdge          `json:"edges"`
+}
+
+func NewKnockoutGraph(tournaments Tournaments) *KnockoutGraph {
+	graph := &KnockoutGraph{Nodes: []*KnockoutNode{}, Edges: []*KnockoutEdge{}}
+	nodes := make(map[uuid.UUID]*KnockoutNode)
+	edges := make(map[[2]uuid.UUID]*KnockoutEdge)
+
+	node := func(player uuid.UUID) *KnockoutNode {
+		n, found := nodes[player]
+		if !found {
+			n = &KnockoutNode{Player: player}
+			nodes[player] = n
+			graph.Nodes = append(graph.Nodes, n)
+		}
+		return n
+	}
+
+	for _, t := range tournaments {
+		if !t.Played {
+			continue
+		}
+		for hunter, victims := range t.BountyHunters {
+			for _, victim := range victims {
+				node(hunter).Knockouts += 1
+				node(victim).KnockedOut += 1
+
+				key := [2]uuid.UUID{hunter, victim}
+				e, found := edges[key]
+				if !found {
+					e = &KnockoutEdge{Hunter: hunter, Victim: victim, BySeason: make(map[int]int)}
+					edges[key] = e
+					graph.Edges = append(graph.Edges, e)
+				}
+				e.Count += 1
+				e.BySeason[t.Info.Season] += 1
+			}
+		}
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Player.String() < graph.Nodes[j].Player.String()
+	})
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Count == b.Count {
+			return a.Hunter.String()+a.Victim.String() < b.Hunter.String()+b.Victim.String()
+		}
+		return a.Count > b.Count
+	})
+	return graph
+}
+
+// The edge with the highest count, edges are already sorted by count
+func firstEdge(edges []*KnockoutEdge, match func(e *KnockoutEdge) bool) *KnockoutEdge {
+	for _, e := range edges {
+		if match(e) {
+			return e
+		}
+	}
+	return nil
+}
+
+func (g *KnockoutGraph) Player(player uuid.UUID) *PlayerKnockouts {
+	pk := &PlayerKnockouts{
+		Player:   player,
+		BySeason: make(map[int]*SeasonKnockouts),
+		Edges:    []*KnockoutEdge{},
+	}
+	season := func(s int) *SeasonKnockouts {
+		if pk.BySeason[s] == nil {
+			pk.BySeason[s] = new(SeasonKnockouts)
+		}
+		return pk.BySeason[s]
+	}
+
+	for _, e := range g.Edges {
+		if e.Hunter != player && e.Victim != player {
+			continue
+		}
+		pk.Edges = append(pk.Edges, e)
+		for s, count := range e.BySeason {
+			if e.Hunter == player {
+				season(s).Knockouts += count
+			} else {
+				season(s).KnockedOut += count
+			}
+		}
+		if e.Hunter == player {
+			pk.Knockouts += e.Count
+		} else {
+			pk.KnockedOut += e.Count
+		}
+	}
+
+	pk.Nemesis = firstEdge(g.Edges, func(e *KnockoutEdge) bool { return e.Victim == player })
+	pk.FavouriteVictim = firstEdge(g.Edges, func(e *KnockoutEdge) bool { return e.Hunter == player })
+	return pk
+}
+
+// Export the graph in Graphviz DOT format, labelling players with the
+// given names where known
+func (g *KnockoutGraph) DOT(names map[uuid.UUID]string) []byte {
+	var b bytes.Buffer
+	b.WriteString("digraph knockouts {\n")
+	for _, n := range g.Nodes {
+		label, found := names[n.Player]
+		if !found {
+			label = n.Player.String()
+		}
+		fmt.Fprintf(&b, "\t\"%s\" [label=%s];\n", n.Player, strconv.Quote(label))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t\"%s\" -> \"%s\" [label=\"%d\", penwidth=%d];\n", e.Hunter, e.Victim, e.Count, e.Count)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
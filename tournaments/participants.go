@@ -0,0 +1,121 @@
+package tournaments
+
+import (
+	"sort"
+
+	"github.com/m4rw3r/uuid"
+)
+
+type ParticipantStatus int
+
+const (
+	Invited ParticipantStatus = iota
+	Registered
+	CheckedIn
+	Finished
+	Noshow
+)
+
+var ParticipantStatusNames = []string{
+	"invited",
+	"registered",
+	"checkedIn",
+	"finished",
+	"noshow",
+}
+
+// A player invited to, registered for or taking part in a tournament.
+// Place is set for finished players, counting from 1.
+type Participant struct {
+	Player uuid.UUID         `json:"player"`
+	Status ParticipantStatus `json:"status"`
+	Place  int               `json:"place,omitempty"`
+	RSVP   RSVPAnswer        `json:"rsvp"`
+}
+
+type Participants []*Participant
+
+func (p Participants) Len() int      { return len(p) }
+func (p Participants) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p Participants) Less(i, j int) bool {
+	if p[i].Status != p[j].Status {
+		return p[i].Status < p[j].Status
+	}
+	if p[i].Place != p[j].Place {
+		return p[i].Place < p[j].Place
+	}
+	return p[i].Player.String() < p[j].Player.String()
+}
+
+// The order players finished the tournament in. Players that checked in
+// but are missing from the recorded result busted without being placed,
+// and are put after the result in the order they checked in.
+func (t *Tournament) FinishOrder() Result {
+	return t.FinishOrderOf(t.Result)
+}
+
+// The order players would finish in with the given result
+func (t *Tournament) FinishOrderOf(result Result) Result {
+	order := append(Result{}, result...)
+	if len(order) == 0 {
+		return order
+	}
+	for _, player := range t.CheckedIn {
+		if placeIn(order, player) == 0 && !t.isNoShow(player) {
+			order = append(order, player)
+		}
+	}
+	return order
+}
+
+func (t *Tournament) isNoShow(player uuid.UUID) bool {
+	for _, a := range t.Noshows {
+		if a.Player == player {
+			return true
+		}
+	}
+	return false
+}
+
+// The participant list of the tournament, built from the invited
+// players, RSVPs, check-ins, the result and noshows. A player is listed
+// with the furthest status reached, and a noshow overrides the rest
+// unless the player finished.
+func (t *Tournament) Participants(invited []uuid.UUID) Participants {
+	byPlayer := make(map[uuid.UUID]*Participant)
+	list := Participants{}
+	participant := func(player uuid.UUID) *Participant {
+		p, found := byPlayer[player]
+		if !found {
+			p = &Participant{Player: player, Status: Invited}
+			byPlayer[player] = p
+			list = append(list, p)
+		}
+		return p
+	}
+
+	for _, player := range invited {
+		participant(player)
+	}
+	for _, rsvp := range t.RSVPs {
+		p := participant(rsvp.Player)
+		p.RSVP = rsvp.Answer
+		if rsvp.Answer == Yes {
+			p.Status = Registered
+		}
+	}
+	for _, player := range t.CheckedIn {
+		participant(player).Status = CheckedIn
+	}
+	for _, a := range t.Noshows {
+		participant(a.Player).Status = Noshow
+	}
+	for i, player := range t.FinishOrder() {
+		p := participant(player)
+		p.Status = Finished
+		p.Place = i + 1
+	}
+
+	sort.Sort(list)
+	return list
+}
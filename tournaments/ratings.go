@@ -0,0 +1,105 @@
+package tournaments
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/m4rw3r/uuid"
+)
+
+const (
+	InitialRating = 1500.0
+	RatingK       = 32.0
+)
+
+type RatingPoint struct {
+	Tournament uuid.UUID `json:"tournament"`
+	When       time.Time `json:"when"`
+	Place      int       `json:"place"`
+	NumPlayers int       `json:"numPlayers"`
+	Rating     float64   `json:"rating"`
+	Change     float64   `json:"change"`
+}
+
+type RatingHistory map[uuid.UUID][]RatingPoint
+
+func expectedScore(rating, opponent float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponent-rating)/400))
+}
+
+// Played tournaments in the order ratings are computed. Tournaments
+// scheduled at the same time are ordered by UUID, so that recomputing
+// always gives the same ratings.
+func chronological(tournaments Tournaments) Tournaments {
+	var played Tournaments
+	for _, t := range tournaments {
+		if t.Played && len(t.FinishOrder()) > 1 {
+			played = append(played, t)
+		}
+	}
+	sort.SliceStable(played, func(i, j int) bool {
+		a, b := played[i], played[j]
+		if a.Info.Scheduled.Equal(b.Info.Scheduled) {
+			return a.UUID.String() < b.UUID.String()
+		}
+		return a.Info.Scheduled.Before(b.Info.Scheduled)
+	})
+	return played
+}
+
+// Compute multiplayer Elo ratings, treating every tournament as a set
+// of head to head matches between all pairs of players in the result.
+// Ratings are always recomputed from scratch.
+func Ratings(tournaments Tournaments) (map[uuid.UUID]float64, RatingHistory) {
+	ratings := make(map[uuid.UUID]float64)
+	history := make(RatingHistory)
+
+	for _, t := range chronological(tournaments) {
+		order := t.FinishOrder()
+		for _, player := range order {
+			if _, found := ratings[player]; !found {
+				ratings[player] = InitialRating
+			}
+		}
+
+		n := len(order)
+		changes := make([]float64, n)
+		for i, player := range order {
+			sum := 0.0
+			for j, opponent := range order {
+				if i == j {
+					continue
+				}
+				score := 0.0
+				if i < j {
+					score = 1.0
+				}
+				sum += score - expectedScore(ratings[player], ratings[opponent])
+			}
+			changes[i] = RatingK * sum / float64(n-1)
+		}
+
+		for i, player := range order {
+			ratings[player] += changes[i]
+			history[player] = append(history[player], RatingPoint{
+				Tournament: t.UUID,
+				When:       t.Info.Scheduled,
+				Place:      i + 1,
+				NumPlayers: n,
+				Rating:     ratings[player],
+				Change:     changes[i],
+			})
+		}
+	}
+	return ratings, history
+}
+
+func PlayerRatingHistory(player uuid.UUID) ([]RatingPoint, error) {
+	tList, err := AllTournaments()
+	if err != nil {
+		return nil, err
+	}
+	_, history := Ratings(tList)
+	return history[player], nil
+}
@@ -0,0 +1,209 @@
+package tournaments
+
+import (
+	"sort"
+	"time"
+
+	"github.com/m4rw3r/uuid"
+)
+
+// A record held by a player. For streaks From and To are the first and
+// last tournament in the streak, for single night records they are the
+// same tournament.
+type Record struct {
+	Player uuid.UUID `json:"uuid"`
+	Value  int       `json:"value"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+type Records []Record
+
+func (r Records) Len() int      { return len(r) }
+func (r Records) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+
+// Equal records are ordered by who set them first, then by player, so
+// the order does not depend on map iteration
+func (r Records) Less(i, j int) bool {
+	if r[i].Value != r[j].Value {
+		return r[i].Value > r[j].Value
+	}
+	if !r[i].From.Equal(r[j].From) {
+		return r[i].From.Before(r[j].From)
+	}
+	return r[i].Player.String() < r[j].Player.String()
+}
+
+// Every list holds the best record of each player, best first
+type RecordBook struct {
+	WinStreak        Records `json:"winStreak"`
+	CashStreak       Records `json:"cashStreak"`
+	LastPlaceStreak  Records `json:"lastPlaceStreak"`
+	BiggestFieldWon  Records `json:"biggestFieldWon"`
+	AttendanceStreak Records `json:"attendanceStreak"`
+	BiggestSwing     Records `json:"biggestSwing"`
+	FastestToWins    Records `json:"fastestToWins"`
+	NumWins          int     `json:"numWins"`
+}
+
+const DefaultRecordWins = 5
+
+type streak struct {
+	length   int
+	from, to time.Time
+}
+
+// Track the current and best streak of every player
+type streaks struct {
+	current map[uuid.UUID]*streak
+	best    map[uuid.UUID]Record
+}
+
+func newStreaks() *streaks {
+	return &streaks{
+		current: make(map[uuid.UUID]*streak),
+		best:    make(map[uuid.UUID]Record),
+	}
+}
+
+func (s *streaks) extend(player uuid.UUID, when time.Time) {
+	cur, found := s.current[player]
+	if !found {
+		cur = &streak{from: when}
+		s.current[player] = cur
+	}
+	cur.length += 1
+	cur.to = when
+	if cur.length > s.best[player].Value {
+		s.best[player] = Record{Player: player, Value: cur.length, From: cur.from, To: cur.to}
+	}
+}
+
+func (s *streaks) end(player uuid.UUID) {
+	delete(s.current, player)
+}
+
+func (s *streaks) records() Records {
+	return sortedRecords(s.best)
+}
+
+func sortedRecords(best map[uuid.UUID]Record) Records {
+	records := Records{}
+	for _, r := range best {
+		records = append(records, r)
+	}
+	sort.Sort(records)
+	return records
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Compute the record book for the given tournaments. Streaks for a
+// player only count tournaments the player took part in, except the
+// attendance streak which is broken by every tournament missed.
+func NewRecordBook(tournaments Tournaments, numWins int) *RecordBook {
+	if numWins <= 0 {
+		numWins = DefaultRecordWins
+	}
+
+	wins, cashes, lastPlaces, attendance := newStreaks(), newStreaks(), newStreaks(), newStreaks()
+	biggestField := make(map[uuid.UUID]Record)
+	biggestSwing := make(map[uuid.UUID]Record)
+	fastest := make(map[uuid.UUID]Record)
+
+	numPlayed := make(map[uuid.UUID]int)
+	numWon := make(map[uuid.UUID]int)
+	debut := make(map[uuid.UUID]time.Time)
+
+	for _, t := range chronological(tournaments) {
+		when := t.Info.Scheduled
+		order := t.FinishOrder()
+		last := len(order) - 1
+		net := t.Accounting().Net
+
+		for player := range attendance.current {
+			if placeIn(order, player) == 0 {
+				attendance.end(player)
+			}
+		}
+
+		for i, player := range order {
+			attendance.extend(player, when)
+
+			numPlayed[player] += 1
+			if numPlayed[player] == 1 {
+				debut[player] = when
+			}
+
+			if i == 0 {
+				wins.extend(player, when)
+				numWon[player] += 1
+				if numWon[player] == numWins {
+					fastest[player] = Record{Player: player, Value: numPlayed[player], From: debut[player], To: when}
+				}
+				if len(order) > biggestField[player].Value {
+					biggestField[player] = Record{Player: player, Value: len(order), From: when, To: when}
+				}
+			} else {
+				wins.end(player)
+			}
+
+			if i <= 1 {
+				cashes.extend(player, when)
+			} else {
+				cashes.end(player)
+			}
+
+			if i == last {
+				lastPlaces.extend(player, when)
+			} else {
+				lastPlaces.end(player)
+			}
+
+			if swing, found := biggestSwing[player]; !found || abs(net[player]) > abs(swing.Value) {
+				biggestSwing[player] = Record{Player: player, Value: net[player], From: when, To: when}
+			}
+		}
+	}
+
+	book := &RecordBook{
+		WinStreak:        wins.records(),
+		CashStreak:       cashes.records(),
+		LastPlaceStreak:  lastPlaces.records(),
+		BiggestFieldWon:  sortedRecords(biggestField),
+		AttendanceStreak: attendance.records(),
+		BiggestSwing:     sortedRecords(biggestSwing),
+		FastestToWins:    sortedRecords(fastest),
+		NumWins:          numWins,
+	}
+
+	sort.SliceStable(book.BiggestSwing, func(i, j int) bool {
+		return abs(book.BiggestSwing[i].Value) > abs(book.BiggestSwing[j].Value)
+	})
+	// Fewest tournaments needed is the best
+	sort.SliceStable(book.FastestToWins, func(i, j int) bool {
+		return book.FastestToWins[i].Value < book.FastestToWins[j].Value
+	})
+	return book
+}
+
+func SeasonRecords(season int, numWins int) (*RecordBook, error) {
+	tList, err := TournamentsBySeason(season)
+	if err != nil {
+		return nil, err
+	}
+	return NewRecordBook(tList, numWins), nil
+}
+
+func TotalRecords(numWins int) (*RecordBook, error) {
+	tList, err := AllTournaments()
+	if err != nil {
+		return nil, err
+	}
+	return NewRecordBook(tList, numWins), nil
+}
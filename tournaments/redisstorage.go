@@ -93,6 +93,64 @@ func (rts *RedisTournamentStorage) LoadBySeason(season int) (Tournaments, error)
 	return tournaments, nil
 }
 
+func (rts *RedisTournamentStorage) StoreRecurrence(r *Recurrence) error {
+	conn := rts.pool.Get()
+	defer conn.Close()
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err = conn.Do("SET", fmt.Sprintf("season:%d:recurrence", r.Season), b); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rts *RedisTournamentStorage) LoadRecurrence(season int) (*Recurrence, error) {
+	conn := rts.pool.Get()
+	defer conn.Close()
+	b, err := redigo.Bytes(conn.Do("GET", fmt.Sprintf("season:%d:recurrence", season)))
+	if err != nil {
+		return nil, err
+	}
+	r := new(Recurrence)
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (rts *RedisTournamentStorage) StoreBetScoring(season int, scoring *BetScoring) error {
+	conn := rts.pool.Get()
+	defer conn.Close()
+	b, err := json.Marshal(scoring)
+	if err != nil {
+		return err
+	}
+	if _, err = conn.Do("SET", fmt.Sprintf("season:%d:betscoring", season), b); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Returns nil without an error if the season has no scoring set
+func (rts *RedisTournamentStorage) LoadBetScoring(season int) (*BetScoring, error) {
+	conn := rts.pool.Get()
+	defer conn.Close()
+	b, err := redigo.Bytes(conn.Do("GET", fmt.Sprintf("season:%d:betscoring", season)))
+	if err == redigo.ErrNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	scoring := new(BetScoring)
+	if err := json.Unmarshal(b, scoring); err != nil {
+		return nil, err
+	}
+	return scoring, nil
+}
+
 func NewRedisTournamentStorage() *RedisTournamentStorage {
 	rts := new(RedisTournamentStorage)
 	rts.pool = &redigo.Pool{
@@ -0,0 +1,164 @@
+package tournaments
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/m4rw3r/uuid"
+)
+
+// A result submitted by a participant, waiting for confirmation by
+// another participant or an admin before it counts
+type ProvisionalResult struct {
+	Result        Result        `json:"result"`
+	BountyHunters BountyHunters `json:"bountyHunters"`
+	SubmittedBy   uuid.UUID     `json:"submittedBy"`
+	Submitted     time.Time     `json:"submitted"`
+}
+
+// All problems found when validating a result
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, ", ")
+}
+
+func (e *ValidationError) add(problem string) {
+	e.Problems = append(e.Problems, problem)
+}
+
+func (e *ValidationError) orNil() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// A player not allowed to do something with a result
+type AuthorizationError struct {
+	Reason string
+}
+
+func (e *AuthorizationError) Error() string {
+	return e.Reason
+}
+
+// Validate a result for the tournament. Players automatically
+// registered as noshows from their RSVP may still be in the result, as
+// those noshows are removed when the result is set. If known is given,
+// every player in the result must be in it.
+func (t *Tournament) ValidateResult(result Result, known []uuid.UUID) error {
+	verr := new(ValidationError)
+	if len(result) == 0 {
+		verr.add("result is empty")
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, p := range result {
+		if p.IsZero() {
+			verr.add("result contains an empty player")
+			continue
+		}
+		if seen[p] {
+			verr.add("player " + p.String() + " is in the result more than once")
+		}
+		seen[p] = true
+		if known != nil && !containsUUID(known, p) {
+			verr.add("player " + p.String() + " is unknown")
+		}
+	}
+
+	for _, a := range t.Noshows {
+		if seen[a.Player] && a.Reason != rsvpNoShowReason {
+			verr.add("player " + a.Player.String() + " is registered as noshow")
+		}
+	}
+	return verr.orNil()
+}
+
+// Validate bounty hunters against a result. Both hunters and victims
+// must be in the result, and a victim can not finish ahead of the hunter.
+func ValidateBountyHunters(bh BountyHunters, result Result) error {
+	verr := new(ValidationError)
+	for hunter, victims := range bh {
+		hunterPlace := placeIn(result, hunter)
+		if hunterPlace == 0 {
+			verr.add("bounty hunter " + hunter.String() + " is not in the result")
+		}
+		for _, victim := range victims {
+			victimPlace := placeIn(result, victim)
+			switch {
+			case victim == hunter:
+				verr.add("bounty hunter " + hunter.String() + " can not knock out itself")
+			case victimPlace == 0:
+				verr.add("knocked out player " + victim.String() + " is not in the result")
+			case hunterPlace != 0 && victimPlace < hunterPlace:
+				verr.add("knocked out player " + victim.String() + " finished ahead of bounty hunter " + hunter.String())
+			}
+		}
+	}
+	return verr.orNil()
+}
+
+// Submit a result to be confirmed before it counts. If known is given,
+// every player in the result must be in it. Results of played
+// tournaments can only be changed by admins, who set them directly.
+func (t *Tournament) SubmitResult(result Result, bh BountyHunters, by uuid.UUID, known []uuid.UUID) error {
+	if t.Played {
+		return &AuthorizationError{"Tournament is already played, and its result can only be changed by admins"}
+	}
+	if err := t.ValidateResult(result, known); err != nil {
+		return err
+	}
+	if err := ValidateBountyHunters(bh, t.FinishOrderOf(result)); err != nil {
+		return err
+	}
+	t.Provisional = &ProvisionalResult{
+		Result:        result,
+		BountyHunters: bh,
+		SubmittedBy:   by,
+		Submitted:     time.Now(),
+	}
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store provisional tournament result")
+	}
+	return nil
+}
+
+// Confirm the provisional result, making it the result of the
+// tournament. Only admins may confirm their own submissions, and other
+// players must be in the result to confirm it. Refusals are returned
+// as an AuthorizationError.
+func (t *Tournament) ConfirmResult(by uuid.UUID, isAdmin bool, known []uuid.UUID) error {
+	p := t.Provisional
+	if p == nil {
+		return errors.New("Tournament has no provisional result")
+	}
+	if !isAdmin {
+		if by == p.SubmittedBy {
+			return &AuthorizationError{"Result must be confirmed by someone else than the submitter"}
+		}
+		if placeIn(p.Result, by) == 0 {
+			return &AuthorizationError{"Only players in the result can confirm it"}
+		}
+	}
+	// The result is validated again by SetResult, as noshows and players
+	// may have changed since it was submitted
+	t.BountyHunters = p.BountyHunters
+	t.Provisional = nil
+	return t.SetResult(p.Result, known)
+}
+
+func (t *Tournament) RejectResult() error {
+	if t.Provisional == nil {
+		return errors.New("Tournament has no provisional result")
+	}
+	t.Provisional = nil
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store tournament with rejected result")
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package tournaments
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ckpt/backend-services/utils"
+	"github.com/m4rw3r/uuid"
+)
+
+type RSVPAnswer int
+
+const (
+	Unanswered RSVPAnswer = iota
+	Yes
+	No
+	Maybe
+)
+
+var RSVPAnswerNames = []string{
+	"unanswered",
+	"yes",
+	"no",
+	"maybe",
+}
+
+type RSVP struct {
+	Player  uuid.UUID  `json:"player"`
+	Answer  RSVPAnswer `json:"answer"`
+	Comment string     `json:"comment"`
+	Updated time.Time  `json:"updated"`
+}
+
+type Headcount struct {
+	Yes      int     `json:"yes"`
+	No       int     `json:"no"`
+	Maybe    int     `json:"maybe"`
+	Expected float64 `json:"expected"`
+}
+
+func (t *Tournament) RSVPByPlayer(player uuid.UUID) (*RSVP, error) {
+	for i := range t.RSVPs {
+		if t.RSVPs[i].Player == player {
+			return &t.RSVPs[i], nil
+		}
+	}
+	return nil, errors.New("RSVP not found")
+}
+
+func (t *Tournament) SetRSVP(player uuid.UUID, answer RSVPAnswer, comment string) error {
+	if t.Played {
+		return errors.New("Tournament is already played")
+	}
+	if answer <= Unanswered || answer > Maybe {
+		return errors.New("RSVP needs to be yes, no or maybe")
+	}
+
+	if rsvp, err := t.RSVPByPlayer(player); err == nil {
+		rsvp.Answer = answer
+		rsvp.Comment = comment
+		rsvp.Updated = time.Now()
+	} else {
+		t.RSVPs = append(t.RSVPs, RSVP{Player: player, Answer: answer, Comment: comment, Updated: time.Now()})
+	}
+
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store tournament with updated RSVP")
+	}
+	return nil
+}
+
+// Maybe answers count as half a player in the expected headcount
+func (t *Tournament) Headcount() Headcount {
+	var hc Headcount
+	for _, rsvp := range t.RSVPs {
+		switch rsvp.Answer {
+		case Yes:
+			hc.Yes += 1
+		case No:
+			hc.No += 1
+		case Maybe:
+			hc.Maybe += 1
+		}
+	}
+	hc.Expected = float64(hc.Yes) + float64(hc.Maybe)/2
+	return hc
+}
+
+func (t *Tournament) MissingRSVPs(players []uuid.UUID) []uuid.UUID {
+	var missing []uuid.UUID
+	for _, player := range players {
+		if _, err := t.RSVPByPlayer(player); err != nil {
+			missing = append(missing, player)
+		}
+	}
+	return missing
+}
+
+// Send a reminder to the given players that has not answered yet
+func (t *Tournament) RemindMissingRSVPs(players []uuid.UUID) ([]uuid.UUID, error) {
+	if t.Played {
+		return nil, errors.New("Tournament is already played")
+	}
+	missing := t.MissingRSVPs(players)
+	if len(missing) == 0 {
+		return missing, nil
+	}
+	err := eventqueue.Publish(utils.CKPTEvent{
+		Type:         utils.TOURNAMENT_EVENT,
+		RestrictedTo: missing,
+		Subject:      "Påmelding til turnering",
+		Message: "Du har ikke svart på om du kommer til turneringen " +
+			t.Info.Scheduled.Format("02.01.2006 15:04") + ". Svar på ckpt.no!"})
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not send RSVP reminders")
+	}
+	return missing, nil
+}
+
+const rsvpNoShowReason = "Meldte seg på, men møtte ikke"
+
+// Players that said yes but are missing from the result are registered
+// as noshows, while noshows that did show up after all are removed.
+func (t *Tournament) reconcileNoShows() {
+	inResult := make(map[uuid.UUID]bool)
+	for _, player := range t.Result {
+		inResult[player] = true
+	}
+	// Checked in players did show up, even if they are not placed
+	for _, player := range t.CheckedIn {
+		inResult[player] = true
+	}
+
+	var noshows []Absentee
+	registered := make(map[uuid.UUID]bool)
+	for _, a := range t.Noshows {
+		if inResult[a.Player] {
+			continue
+		}
+		registered[a.Player] = true
+		noshows = append(noshows, a)
+	}
+
+	for _, rsvp := range t.RSVPs {
+		if rsvp.Answer != Yes || inResult[rsvp.Player] || registered[rsvp.Player] {
+			continue
+		}
+		noshows = append(noshows, Absentee{
+			Player:   rsvp.Player,
+			Reported: time.Now(),
+			Reason:   rsvpNoShowReason,
+		})
+	}
+	t.Noshows = noshows
+}
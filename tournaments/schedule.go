@@ -0,0 +1,214 @@
+package tournaments
+
+import (
+	"errors"
+	"os"
+	"time"
+	// The runtime image has no zoneinfo, so embed it
+	_ "time/tzdata"
+
+	"github.com/ckpt/backend-services/utils"
+	"github.com/m4rw3r/uuid"
+)
+
+// A Recurrence describes when tournaments in a season are normally
+// played, e.g. the first (Week 1) Friday every month at 19:30.
+// Week -1 means the last given weekday of the month.
+type Recurrence struct {
+	Season   int          `json:"season"`
+	Weekday  time.Weekday `json:"weekday"`
+	Week     int          `json:"week"`
+	Hour     int          `json:"hour"`
+	Minute   int          `json:"minute"`
+	Stake    int          `json:"stake"`
+	Location uuid.UUID    `json:"location"`
+	Months   []time.Month `json:"months"`
+	Exclude  []time.Time  `json:"exclude"`
+}
+
+type Reschedule struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Reason string    `json:"reason"`
+	By     uuid.UUID `json:"by"`
+	When   time.Time `json:"when"`
+}
+
+// The time zone tournaments are scheduled in, from CKPT_TIMEZONE
+var LeagueLocation = leagueLocation(os.Getenv("CKPT_TIMEZONE"))
+
+const defaultLeagueTimezone = "Europe/Oslo"
+
+func leagueLocation(name string) *time.Location {
+	if name == "" {
+		name = defaultLeagueTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+func validateRecurrence(r *Recurrence) error {
+	if r.Season == 0 {
+		return errors.New("Recurrence needs a season")
+	}
+	if r.Stake == 0 {
+		return errors.New("Recurrence needs a stake")
+	}
+	if r.Week == 0 || r.Week < -1 || r.Week > 5 {
+		return errors.New("Recurrence week must be 1-5, or -1 for the last week")
+	}
+	if r.Weekday < time.Sunday || r.Weekday > time.Saturday {
+		return errors.New("Recurrence needs a valid weekday")
+	}
+	if r.Hour < 0 || r.Hour > 23 || r.Minute < 0 || r.Minute > 59 {
+		return errors.New("Recurrence needs a valid time of day")
+	}
+	return nil
+}
+
+// Whether the times are on the same day in the league time zone
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.In(LeagueLocation).Date()
+	by, bm, bd := b.In(LeagueLocation).Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// Find the nth weekday of the given month. Returns false if the month
+// does not have that many of the given weekday.
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n, hour, minute int) (time.Time, bool) {
+	if n == -1 {
+		last := time.Date(year, month+1, 0, hour, minute, 0, 0, LeagueLocation)
+		offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+		return last.AddDate(0, 0, -offset), true
+	}
+	first := time.Date(year, month, 1, hour, minute, 0, 0, LeagueLocation)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	date := first.AddDate(0, 0, offset+(n-1)*7)
+	if date.Month() != month {
+		return date, false
+	}
+	return date, true
+}
+
+// The dates given by the recurrence, without any excluded dates
+func (r *Recurrence) Dates() []time.Time {
+	months := r.Months
+	if len(months) == 0 {
+		for m := time.January; m <= time.December; m++ {
+			months = append(months, m)
+		}
+	}
+
+	var dates []time.Time
+	for _, m := range months {
+		date, ok := nthWeekday(r.Season, m, r.Weekday, r.Week, r.Hour, r.Minute)
+		if !ok {
+			continue
+		}
+		excluded := false
+		for _, e := range r.Exclude {
+			if sameDay(date, e) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+func SetRecurrence(r *Recurrence) error {
+	if err := validateRecurrence(r); err != nil {
+		return errors.New(err.Error() + " - Could not set recurrence")
+	}
+	if err := storage.StoreRecurrence(r); err != nil {
+		return errors.New(err.Error() + " - Could not write recurrence to storage")
+	}
+	return nil
+}
+
+func RecurrenceBySeason(season int) (*Recurrence, error) {
+	return storage.LoadRecurrence(season)
+}
+
+// Create planned tournaments for the season recurrence. Dates that
+// already has a tournament, or had one before it was moved, are skipped.
+func GenerateSchedule(season int) (Tournaments, error) {
+	r, err := storage.LoadRecurrence(season)
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not load recurrence for season")
+	}
+	existing, err := storage.LoadBySeason(season)
+	if err != nil {
+		return nil, errors.New(err.Error() + " - Could not load tournaments for season")
+	}
+
+	var created Tournaments
+	for _, date := range r.Dates() {
+		taken := false
+		for _, t := range existing {
+			if sameDay(t.Info.Scheduled, date) {
+				taken = true
+				break
+			}
+			for _, rs := range t.Reschedules {
+				if sameDay(rs.From, date) {
+					taken = true
+					break
+				}
+			}
+		}
+		if taken {
+			continue
+		}
+		t, err := NewTournament(Info{
+			Scheduled: date,
+			Stake:     r.Stake,
+			Location:  r.Location,
+			Season:    season,
+		})
+		if err != nil {
+			return created, err
+		}
+		created = append(created, t)
+	}
+	return created, nil
+}
+
+func (t *Tournament) Reschedule(to time.Time, reason string, by uuid.UUID) error {
+	if t.Played {
+		return errors.New("Can not reschedule a played tournament")
+	}
+	if to.IsZero() {
+		return errors.New("Rescheduling needs a new date")
+	}
+
+	t.Reschedules = append(t.Reschedules, Reschedule{
+		From:   t.Info.Scheduled,
+		To:     to,
+		Reason: reason,
+		By:     by,
+		When:   time.Now(),
+	})
+	// MovedFrom keeps the originally planned date, the full history is
+	// in Reschedules
+	if t.Info.MovedFrom.IsZero() {
+		t.Info.MovedFrom = t.Info.Scheduled
+	}
+	t.Info.Scheduled = to
+	t.Moved = true
+
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store rescheduled tournament")
+	}
+	eventqueue.Publish(utils.CKPTEvent{
+		Type:    utils.TOURNAMENT_EVENT,
+		Subject: "Turnering flyttet",
+		Message: "En turnering er flyttet til " + to.Format("02.01.2006 15:04") + ". Se ckpt.no!"})
+	return nil
+}
@@ -0,0 +1,259 @@
+package tournaments
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/m4rw3r/uuid"
+)
+
+type Table struct {
+	Number int         `json:"number"`
+	Seats  []uuid.UUID `json:"seats"`
+}
+
+type Seating struct {
+	TableSize int       `json:"tableSize"`
+	Tables    []*Table  `json:"tables"`
+	Drawn     time.Time `json:"drawn"`
+}
+
+// Constraints for the seat draw. Fixed maps players to the table
+// number they must be seated at, e.g. the host at table 1.
+type SeatingConstraints struct {
+	TableSize int               `json:"tableSize"`
+	Fixed     map[uuid.UUID]int `json:"fixed"`
+}
+
+type SeatMove struct {
+	Player    uuid.UUID `json:"player"`
+	FromTable int       `json:"fromTable"`
+	ToTable   int       `json:"toTable"`
+}
+
+const DefaultTableSize = 10
+
+func (t *Tournament) IsCheckedIn(player uuid.UUID) bool {
+	for _, p := range t.CheckedIn {
+		if p == player {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tournament) CheckIn(player uuid.UUID) error {
+	if t.Played {
+		return errors.New("Tournament is already played")
+	}
+	if t.IsCheckedIn(player) {
+		return errors.New("Player is already checked in")
+	}
+	t.CheckedIn = append(t.CheckedIn, player)
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store tournament with checked in player")
+	}
+	return nil
+}
+
+// Checked in players count as finishers once the result is in, so they
+// can only check out before the tournament is played
+func (t *Tournament) CheckOut(player uuid.UUID) error {
+	if t.Played {
+		return errors.New("Tournament is already played")
+	}
+	if !t.IsCheckedIn(player) {
+		return errors.New("Player is not checked in")
+	}
+	for i, p := range t.CheckedIn {
+		if p == player {
+			t.CheckedIn = append(t.CheckedIn[:i], t.CheckedIn[i+1:]...)
+			break
+		}
+	}
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store tournament with checked out player")
+	}
+	return nil
+}
+
+// Randomly draw seats for the checked in players, spreading them as
+// evenly as possible over the least number of tables needed.
+func (t *Tournament) DrawSeating(constraints SeatingConstraints, rng *rand.Rand) error {
+	if t.Played {
+		return errors.New("Tournament is already played")
+	}
+	players := t.CheckedIn
+	if len(players) < 2 {
+		return errors.New("Need at least two checked in players to draw seats")
+	}
+	tableSize := constraints.TableSize
+	if tableSize == 0 {
+		tableSize = DefaultTableSize
+	}
+	if tableSize < 2 {
+		return errors.New("Tables must seat at least two players")
+	}
+
+	numTables := (len(players) + tableSize - 1) / tableSize
+	capacity := make([]int, numTables)
+	for i := range capacity {
+		capacity[i] = len(players) / numTables
+		if i < len(players)%numTables {
+			capacity[i] += 1
+		}
+	}
+
+	tables := make([]*Table, numTables)
+	for i := range tables {
+		tables[i] = &Table{Number: i + 1}
+	}
+
+	var free []uuid.UUID
+	for _, p := range players {
+		n, fixed := constraints.Fixed[p]
+		if fixed && n >= 1 && n <= numTables && len(tables[n-1].Seats) < capacity[n-1] {
+			tables[n-1].Seats = append(tables[n-1].Seats, p)
+		} else {
+			free = append(free, p)
+		}
+	}
+
+	rng.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+	for _, table := range tables {
+		for len(table.Seats) < capacity[table.Number-1] && len(free) > 0 {
+			table.Seats = append(table.Seats, free[0])
+			free = free[1:]
+		}
+		rng.Shuffle(len(table.Seats), func(i, j int) {
+			table.Seats[i], table.Seats[j] = table.Seats[j], table.Seats[i]
+		})
+	}
+
+	t.Seating = &Seating{TableSize: tableSize, Tables: tables, Drawn: time.Now()}
+	if err := storage.Store(t); err != nil {
+		return errors.New(err.Error() + " - Could not store tournament seating")
+	}
+	return nil
+}
+
+// Tables with eliminated players removed
+func (t *Tournament) activeTables() []*Table {
+	var tables []*Table
+	if t.Seating == nil {
+		return tables
+	}
+	for _, table := range t.Seating.Tables {
+		active := &Table{Number: table.Number}
+		for _, p := range table.Seats {
+			if !t.eliminated(p) {
+				active.Seats = append(active.Seats, p)
+			}
+		}
+		if len(active.Seats) > 0 {
+			tables = append(tables, active)
+		}
+	}
+	return tables
+}
+
+func smallestTable(tables []*Table, except *Table) *Table {
+	var smallest *Table
+	for _, table := range tables {
+		if table == except {
+			continue
+		}
+		if smallest == nil || len(table.Seats) < len(smallest.Seats) {
+			smallest = table
+		}
+	}
+	return smallest
+}
+
+func largestTable(tables []*Table) *Table {
+	var largest *Table
+	for _, table := range tables {
+		if largest == nil || len(table.Seats) > len(largest.Seats) {
+			largest = table
+		}
+	}
+	return largest
+}
+
+// Compute the moves needed to balance the tables after eliminations.
+// A table is broken up when the remaining players fit at one table
+// less, otherwise players are moved from the largest to the smallest
+// table until they differ by at most one player.
+func balanceTables(tables []*Table, tableSize int) []SeatMove {
+	moves := []SeatMove{}
+
+	total := 0
+	for _, table := range tables {
+		total += len(table.Seats)
+	}
+
+	for len(tables) > 1 && total <= (len(tables)-1)*tableSize {
+		broken := tables[len(tables)-1]
+		for _, table := range tables {
+			if len(table.Seats) < len(broken.Seats) {
+				broken = table
+			}
+		}
+		var remaining []*Table
+		for _, table := range tables {
+			if table != broken {
+				remaining = append(remaining, table)
+			}
+		}
+		for _, p := range broken.Seats {
+			to := smallestTable(remaining, nil)
+			to.Seats = append(to.Seats, p)
+			moves = append(moves, SeatMove{Player: p, FromTable: broken.Number, ToTable: to.Number})
+		}
+		broken.Seats = nil
+		tables = remaining
+	}
+
+	for len(tables) > 1 {
+		from := largestTable(tables)
+		to := smallestTable(tables, from)
+		if len(from.Seats)-len(to.Seats) <= 1 {
+			break
+		}
+		p := from.Seats[len(from.Seats)-1]
+		from.Seats = from.Seats[:len(from.Seats)-1]
+		to.Seats = append(to.Seats, p)
+		moves = append(moves, SeatMove{Player: p, FromTable: from.Number, ToTable: to.Number})
+	}
+
+	return moves
+}
+
+func (t *Tournament) BalanceMoves() []SeatMove {
+	if t.Seating == nil {
+		return []SeatMove{}
+	}
+	return balanceTables(t.activeTables(), t.Seating.TableSize)
+}
+
+// Apply the suggested moves, leaving only players still in the game seated
+func (t *Tournament) BalanceTables() ([]SeatMove, error) {
+	if t.Seating == nil {
+		return nil, errors.New("Tournament has no seating")
+	}
+	tables := t.activeTables()
+	moves := balanceTables(tables, t.Seating.TableSize)
+
+	var seated []*Table
+	for _, table := range tables {
+		if len(table.Seats) > 0 {
+			seated = append(seated, table)
+		}
+	}
+	t.Seating.Tables = seated
+	if err := storage.Store(t); err != nil {
+		return nil, errors.New(err.Error() + " - Could not store tournament with balanced tables")
+	}
+	return moves, nil
+}
@@ -0,0 +1,232 @@
+package tournaments
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/m4rw3r/uuid"
+)
+
+const (
+	DefaultSimulationRuns = 1000
+	MaxSimulationRuns     = 10000
+)
+
+// Chances of a player ending the season with the titles, in the range
+// 0 to 1. MagicNumber is the number of remaining tournaments the player
+// must win to be sure of becoming champion, 0 if already clinched and
+// -1 if it can not be clinched without help from others.
+type PlayerOdds struct {
+	Player       uuid.UUID `json:"uuid"`
+	Champion     float64   `json:"champion"`
+	PointsWinner float64   `json:"pointsWinner"`
+	Enough       float64   `json:"playedEnough"`
+	MagicNumber  int       `json:"magicNumber"`
+}
+
+type Simulation struct {
+	Season    int           `json:"season"`
+	Runs      int           `json:"runs"`
+	Seed      int64         `json:"seed"`
+	Remaining int           `json:"remaining"`
+	Odds      []*PlayerOdds `json:"odds"`
+}
+
+// How a player has performed so far, used to sample future results
+type playerModel struct {
+	player     uuid.UUID
+	attendance float64
+	places     []float64
+}
+
+// Relative place from 0 (winner) to 1 (last)
+func relativePlace(r PlayerResult) float64 {
+	if r.NumPlayers < 2 {
+		return 0
+	}
+	return float64(r.Place-1) / float64(r.NumPlayers-1)
+}
+
+func playerModels(standings PlayerStandings) []*playerModel {
+	var models []*playerModel
+	for _, ps := range standings {
+		if ps.NumTotal == 0 || len(ps.Results) == 0 {
+			continue
+		}
+		m := &playerModel{
+			player:     ps.Player,
+			attendance: float64(ps.NumPlayed) / float64(ps.NumTotal),
+		}
+		for _, r := range ps.Results {
+			m.places = append(m.places, relativePlace(r))
+		}
+		models = append(models, m)
+	}
+	// Map iteration order decides the order of the standings, so sort
+	// to make the simulation reproducible from the seed
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].player.String() < models[j].player.String()
+	})
+	return models
+}
+
+// Sample a result for a tournament: every player attends with their
+// historical attendance rate, and finishes according to a randomly
+// picked relative place from their earlier results.
+func sampleResult(models []*playerModel, rng *rand.Rand) Result {
+	type sample struct {
+		player uuid.UUID
+		place  float64
+	}
+	var samples []sample
+	for _, m := range models {
+		if rng.Float64() < m.attendance {
+			samples = append(samples, sample{m.player, m.places[rng.Intn(len(m.places))]})
+		}
+	}
+	rng.Shuffle(len(samples), func(i, j int) { samples[i], samples[j] = samples[j], samples[i] })
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].place < samples[j].place })
+
+	var result Result
+	for _, s := range samples {
+		result = append(result, s.player)
+	}
+	return result
+}
+
+// A conservative number of wins needed to clinch the championship. The
+// player is assumed to play and lose every other remaining tournament,
+// while any rival wins every tournament the player does not. Every
+// tournament is assumed to have the average field of the season.
+func magicNumber(player *PlayerStanding, standings PlayerStandings, remaining int, stake int, field int) int {
+	gain := stake * (field - 2)
+	if gain < 0 {
+		gain = 0
+	}
+	if player.NumPlayed+remaining < EnoughTournaments {
+		return -1
+	}
+
+	for k := 0; k <= remaining; k++ {
+		worst := player.Winnings + k*gain - (remaining-k)*stake
+		clinched := true
+		for _, rival := range standings {
+			if rival.Player == player.Player || rival.NumPlayed+remaining < EnoughTournaments {
+				continue
+			}
+			if rival.Winnings+(remaining-k)*gain >= worst {
+				clinched = false
+				break
+			}
+		}
+		if clinched {
+			return k
+		}
+	}
+	return -1
+}
+
+// Simulate the rest of the season a number of times, sampling results
+// of the remaining scheduled tournaments from the results so far.
+func SimulateSeason(season int, runs int, seed int64) (*Simulation, error) {
+	tList, err := TournamentsBySeason(season)
+	if err != nil {
+		return nil, err
+	}
+	if runs <= 0 {
+		runs = DefaultSimulationRuns
+	}
+	if runs > MaxSimulationRuns {
+		runs = MaxSimulationRuns
+	}
+
+	var played, remaining Tournaments
+	for _, t := range tList {
+		if t.Played {
+			played = append(played, t)
+		} else {
+			remaining = append(remaining, t)
+		}
+	}
+
+	sim := &Simulation{Season: season, Runs: runs, Seed: seed, Remaining: len(remaining), Odds: []*PlayerOdds{}}
+	standings := NewStandings(played, nil)
+	models := playerModels(standings)
+
+	odds := make(map[uuid.UUID]*PlayerOdds)
+	for _, m := range models {
+		odds[m.player] = &PlayerOdds{Player: m.player}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	for run := 0; run < runs; run++ {
+		simulated := append(Tournaments{}, played...)
+		for _, t := range remaining {
+			simulated = append(simulated, &Tournament{
+				UUID:   t.UUID,
+				Info:   t.Info,
+				Played: true,
+				Result: sampleResult(models, rng),
+			})
+		}
+
+		final := NewStandings(simulated, nil)
+		for _, ps := range final {
+			if o, found := odds[ps.Player]; found && ps.Enough {
+				o.Enough += 1
+			}
+		}
+
+		// Titles are decided like in Titles, tie-breaks included
+		data := &tieBreakData{standings: standingsByPlayer(final)}
+		enough := enoughPlayers(final)
+		champion, err := data.decide(championRuleset(season), enough)
+		if err != nil {
+			return nil, err
+		}
+		if o, found := odds[champion.Winner()]; found {
+			o.Champion += 1
+		}
+		pointsWinner, err := data.decide(Rulesets["pointsWinner"], enough)
+		if err != nil {
+			return nil, err
+		}
+		if o, found := odds[pointsWinner.Winner()]; found {
+			o.PointsWinner += 1
+		}
+	}
+
+	stake, field := 0, 0
+	for _, t := range remaining {
+		stake += t.Info.Stake
+	}
+	if len(remaining) > 0 {
+		stake /= len(remaining)
+	}
+	for _, t := range played {
+		field += len(t.FinishOrder())
+	}
+	if len(played) > 0 {
+		field /= len(played)
+	}
+
+	for _, ps := range standings {
+		o, found := odds[ps.Player]
+		if !found {
+			continue
+		}
+		o.Champion /= float64(runs)
+		o.PointsWinner /= float64(runs)
+		o.Enough /= float64(runs)
+		o.MagicNumber = magicNumber(ps, standings, len(remaining), stake, field)
+		sim.Odds = append(sim.Odds, o)
+	}
+	sort.Slice(sim.Odds, func(i, j int) bool {
+		a, b := sim.Odds[i], sim.Odds[j]
+		if a.Champion == b.Champion {
+			return a.Player.String() < b.Player.String()
+		}
+		return a.Champion > b.Champion
+	})
+	return sim, nil
+}
@@ -7,6 +7,9 @@ import (
 	"github.com/m4rw3r/uuid"
 )
 
+// Tournaments a player must play in a season to qualify for the titles
+const EnoughTournaments = 11
+
 type PlayerResult struct {
 	Place      int       `json:"place"`
 	When       time.Time `json:"when"`
@@ -99,6 +102,7 @@ type PlayerStanding struct {
 	Enough     bool          `json:"playedEnough"`
 	NumTotal   int           `json:"numTotal"`
 	Knockouts  int           `json:"knockouts"`
+	Rating     float64       `json:"rating"`
 }
 
 func (s *PlayerStanding) Equals(t *PlayerStanding) bool {
@@ -108,7 +112,7 @@ func (s *PlayerStanding) Equals(t *PlayerStanding) bool {
 	if !s.Results.Equals(t.Results) {
 		return false
 	}
-	if s.Winnings != t.Winnings || s.AvgPlace != t.AvgPlace || s.Points != t.Points || s.NumHeadsUp != t.NumHeadsUp || s.NumWins != t.NumWins || s.NumPlayed != t.NumPlayed || s.NumTotal != t.NumTotal || s.Knockouts != t.Knockouts {
+	if s.Winnings != t.Winnings || s.AvgPlace != t.AvgPlace || s.Points != t.Points || s.NumHeadsUp != t.NumHeadsUp || s.NumWins != t.NumWins || s.NumPlayed != t.NumPlayed || s.NumTotal != t.NumTotal || s.Knockouts != t.Knockouts || s.Rating != t.Rating {
 		return false
 	}
 	return true
@@ -135,9 +139,10 @@ func (existingPS PlayerStandings) Combine(newPS PlayerStandings) PlayerStandings
 				cps.NumHeadsUp = ops.NumHeadsUp + nps.NumHeadsUp
 				cps.NumWins = ops.NumWins + nps.NumWins
 				cps.NumPlayed = ops.NumPlayed + nps.NumPlayed
-				cps.Enough = cps.NumPlayed > 10
+				cps.Enough = cps.NumPlayed >= EnoughTournaments
 				cps.NumTotal = ops.NumTotal + nps.NumTotal
 				cps.Knockouts = ops.Knockouts + nps.Knockouts
+				cps.Rating = nps.Rating
 				cps.AvgPlace = ((ops.AvgPlace * float64(ops.NumPlayed)) + (nps.AvgPlace * float64(nps.NumPlayed))) / float64(cps.NumPlayed)
 
 				combined = append(combined, cps)
@@ -175,6 +180,7 @@ type SortedStandings struct {
 	ByWinRatioTotal PlayerStandings `json:"byWinRatioTotal"`
 	ByNumPlayed     PlayerStandings `json:"byNumPlayed"`
 	ByKnockouts     PlayerStandings `json:"byKnockouts"`
+	ByRating        PlayerStandings `json:"byRating"`
 }
 
 type ByWinnings struct{ PlayerStandings }
@@ -385,6 +391,21 @@ func (s ByKnockouts) Less(i, j int) bool {
 	return false
 }
 
+type ByRating struct{ PlayerStandings }
+
+func (s ByRating) Less(i, j int) bool {
+	if s.PlayerStandings[i].Rating > s.PlayerStandings[j].Rating {
+		return true
+	}
+
+	if s.PlayerStandings[i].Rating == s.PlayerStandings[j].Rating {
+		if s.PlayerStandings[i].Winnings > s.PlayerStandings[j].Winnings {
+			return true
+		}
+	}
+	return false
+}
+
 func getActivePlayers(tournaments Tournaments) ([]uuid.UUID, int) {
 	var activePlayers []uuid.UUID
 
@@ -395,12 +416,16 @@ func getActivePlayers(tournaments Tournaments) ([]uuid.UUID, int) {
 			continue
 		}
 
-		for _, player := range t.Result {
-			seenPlayer[player] = true
+		finished := 0
+		for _, p := range t.Participants(nil) {
+			if p.Status == Finished {
+				seenPlayer[p.Player] = true
+				finished += 1
+			}
 		}
 
-		if len(t.Result) > maxPlayers {
-			maxPlayers = len(t.Result)
+		if finished > maxPlayers {
+			maxPlayers = finished
 		}
 	}
 
@@ -411,8 +436,10 @@ func getActivePlayers(tournaments Tournaments) ([]uuid.UUID, int) {
 	return activePlayers, maxPlayers
 }
 
+// Ratings are computed over all tournaments, not just the given ones,
+// so they are passed in. Nil ratings leave them at 0.
 // TODO: Split into smaller functions
-func NewStandings(tournaments Tournaments) PlayerStandings {
+func NewStandings(tournaments Tournaments, ratings map[uuid.UUID]float64) PlayerStandings {
 
 	// First, find all active players for these tournaments
 	// Also, get the max number of players for a given set of tournaments
@@ -435,29 +462,29 @@ func NewStandings(tournaments Tournaments) PlayerStandings {
 			continue
 		}
 		numTotal += 1
+		net := t.Accounting().Net
 		seenPlayer := make(map[uuid.UUID]bool)
-		for i, player := range t.Result {
+		order := t.FinishOrder()
+		for i, player := range order {
 			place := i + 1
 			results[player] = append(results[player], PlayerResult{
 				Place:      place,
 				When:       t.Info.Scheduled,
-				NumPlayers: len(t.Result),
+				NumPlayers: len(order),
 			})
 
 			sumPlace[player] += place
 			numPlayed[player] += 1
 			seenPlayer[player] = true
 			points[player] = append(points[player], place)
+			winnings[player] += net[player]
 
 			switch place {
 			case 1:
 				numWins[player] += 1
-				winnings[player] += (len(t.Result) - 2) * t.Info.Stake
 				numHeadsUp[player] += 1
 			case 2:
 				numHeadsUp[player] += 1
-			default:
-				winnings[player] -= t.Info.Stake
 			}
 		}
 
@@ -498,10 +525,7 @@ func NewStandings(tournaments Tournaments) PlayerStandings {
 
 		// Check if player has enough tournaments
 		// TODO: Is this a property of season?
-		enough := false
-		if numPlayed[player] >= 11 {
-			enough = true
-		}
+		enough := numPlayed[player] >= EnoughTournaments
 
 		standings = append(standings, &PlayerStanding{
 			Player:     player,
@@ -515,74 +539,19 @@ func NewStandings(tournaments Tournaments) PlayerStandings {
 			Enough:     enough,
 			NumTotal:   numTotal,
 			Knockouts:  knockouts[player],
+			Rating:     ratings[player],
 		})
 	}
 
 	return standings
 }
 
-func TotalStandings(seasons []int) *SortedStandings {
-
-	sortedStandings := new(SortedStandings)
-
-	var totalStandings PlayerStandings
-	for _, season := range seasons {
-		tList, err := TournamentsBySeason(season)
-		if err != nil {
-			// TODO
-		}
-		standings := NewStandings(tList)
-		totalStandings = totalStandings.Combine(standings)
-	}
-
-	// Got to use new rules to tie break here..
-	totalStandings.ByWinnings(false)
-	sortedStandings.ByWinnings = totalStandings
-
-	totalStandings = totalStandings.Duplicate()
-	totalStandings.ByAvgPlace()
-	sortedStandings.ByAvgPlace = totalStandings
-
-	totalStandings = totalStandings.Duplicate()
-	totalStandings.ByPoints()
-	sortedStandings.ByPoints = totalStandings
-
-	totalStandings = totalStandings.Duplicate()
-	totalStandings.ByHeadsUp()
-	sortedStandings.ByHeadsUp = totalStandings
-
-	totalStandings = totalStandings.Duplicate()
-	totalStandings.ByWinRatio()
-	sortedStandings.ByWinRatio = totalStandings
-
-	totalStandings = totalStandings.Duplicate()
-	totalStandings.ByWinRatioTotal()
-	sortedStandings.ByWinRatioTotal = totalStandings
-
-	totalStandings = totalStandings.Duplicate()
-	totalStandings.ByNumPlayed()
-	sortedStandings.ByNumPlayed = totalStandings
-
-	totalStandings = totalStandings.Duplicate()
-	totalStandings.ByKnockouts()
-	sortedStandings.ByKnockouts = totalStandings
-
-	return sortedStandings
-
-}
-
-func SeasonStandings(season int) *SortedStandings {
-
-	tList, err := TournamentsBySeason(season)
-	if err != nil {
-		// TODO
-	}
-
-	// Compute standings
+// Sort standings in every way the clients show them, each list being
+// a separate copy of the standings
+func sortStandings(standings PlayerStandings, oldTieBreak bool) *SortedStandings {
 	sortedStandings := new(SortedStandings)
 
-	standings := NewStandings(tList)
-	standings.ByWinnings(season < 2013)
+	standings.ByWinnings(oldTieBreak)
 	sortedStandings.ByWinnings = standings
 
 	standings = standings.Duplicate()
@@ -613,9 +582,89 @@ func SeasonStandings(season int) *SortedStandings {
 	standings.ByKnockouts()
 	sortedStandings.ByKnockouts = standings
 
+	standings = standings.Duplicate()
+	standings.ByRating()
+	sortedStandings.ByRating = standings
+
 	return sortedStandings
 }
 
+func TotalStandings(seasons []int) (*SortedStandings, error) {
+
+	var totalStandings PlayerStandings
+	for _, season := range seasons {
+		standings, err := cachedStandings(season)
+		if err != nil {
+			return nil, err
+		}
+		totalStandings = totalStandings.Combine(standings)
+	}
+
+	// Ratings carry over between seasons, so they can not be combined
+	ratings := cachedRatings()
+	for _, ps := range totalStandings {
+		ps.Rating = ratings[ps.Player]
+	}
+
+	// Got to use new rules to tie break here..
+	return sortStandings(totalStandings, false), nil
+}
+
+func SeasonStandings(season int) (*SortedStandings, error) {
+
+	standings, err := cachedStandings(season)
+	if err != nil {
+		return nil, err
+	}
+	return sortStandings(standings, season < 2013), nil
+}
+
+// Limits standings to tournaments in a period and/or at some locations,
+// and optionally to some players. Empty lists means no limit.
+type StandingsFilter struct {
+	From      time.Time
+	To        time.Time
+	Players   []uuid.UUID
+	Locations []uuid.UUID
+}
+
+func containsUUID(list []uuid.UUID, u uuid.UUID) bool {
+	for _, l := range list {
+		if l == u {
+			return true
+		}
+	}
+	return false
+}
+
+// Standings over an arbitrary selection of tournaments. All players in
+// the tournaments count when computing points, but only the selected
+// players are included in the standings.
+func FilteredStandings(filter StandingsFilter) (*SortedStandings, error) {
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+	tList, err := TournamentsByPeriod(filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected Tournaments
+	for _, t := range tList {
+		if len(filter.Locations) == 0 || containsUUID(filter.Locations, t.Info.Location) {
+			selected = append(selected, t)
+		}
+	}
+
+	var standings PlayerStandings
+	for _, ps := range NewStandings(selected, cachedRatings()) {
+		if len(filter.Players) == 0 || containsUUID(filter.Players, ps.Player) {
+			standings = append(standings, ps)
+		}
+	}
+	return sortStandings(standings, false), nil
+}
+
 // Various ways to sort the player standings using helper structs that
 // implement different comparison methods.
 
@@ -662,3 +711,7 @@ func (s PlayerStandings) ByWorstPlayer() {
 func (s PlayerStandings) ByKnockouts() {
 	sort.Stable(ByKnockouts{s})
 }
+
+func (s PlayerStandings) ByRating() {
+	sort.Stable(ByRating{s})
+}
@@ -15,11 +15,12 @@ type YellowPeriod struct {
 }
 
 type MonthStats struct {
-	Year         int        `json:"year"`
-	Month        time.Month `json:"month"`
-	Best         uuid.UUID  `json:"best"`
-	Worst        uuid.UUID  `json:"worst"`
-	BountyHunter uuid.UUID  `json:"bountyhunter"`
+	Year         int               `json:"year"`
+	Month        time.Month        `json:"month"`
+	Best         uuid.UUID         `json:"best"`
+	Worst        uuid.UUID         `json:"worst"`
+	BountyHunter uuid.UUID         `json:"bountyhunter"`
+	DecidedBy    map[string]string `json:"decidedBy"`
 }
 
 type PeriodStats struct {
@@ -27,73 +28,57 @@ type PeriodStats struct {
 	MonthStats    []*MonthStats  `json:"monthStats"`
 }
 
+// Every title tells which tie-break criterion decided it, and who it
+// is shared with if the ruleset allows shared titles
 type SeasonTitles struct {
 	Season   int `json:"season"`
 	Champion struct {
-		Uuid     uuid.UUID `json:"uuid"`
-		Winnings int       `json:"winnings"`
+		Uuid       uuid.UUID   `json:"uuid"`
+		Winnings   int         `json:"winnings"`
+		DecidedBy  string      `json:"decidedBy"`
+		SharedWith []uuid.UUID `json:"sharedWith,omitempty"`
 	} `json:"champion"`
 	AvgPlaceWinner struct {
-		Uuid     uuid.UUID `json:"uuid"`
-		AvgPlace float64   `json:"avgPlace"`
+		Uuid       uuid.UUID   `json:"uuid"`
+		AvgPlace   float64     `json:"avgPlace"`
+		DecidedBy  string      `json:"decidedBy"`
+		SharedWith []uuid.UUID `json:"sharedWith,omitempty"`
 	} `json:"avgPlaceWinner"`
 	PointsWinner struct {
-		Uuid   uuid.UUID `json:"uuid"`
-		Points int       `json:"points"`
+		Uuid       uuid.UUID   `json:"uuid"`
+		Points     int         `json:"points"`
+		DecidedBy  string      `json:"decidedBy"`
+		SharedWith []uuid.UUID `json:"sharedWith,omitempty"`
 	} `json:"pointsWinner"`
 	MostYellowDays struct {
-		Uuid uuid.UUID `json:"uuid"`
-		Days int       `json:"days"`
+		Uuid       uuid.UUID   `json:"uuid"`
+		Days       int         `json:"days"`
+		DecidedBy  string      `json:"decidedBy"`
+		SharedWith []uuid.UUID `json:"sharedWith,omitempty"`
 	} `json:"mostYellowDays"`
 	PlayerOfTheYear struct {
-		Uuid   uuid.UUID `json:"uuid"`
-		Months int       `json:"months"`
+		Uuid       uuid.UUID   `json:"uuid"`
+		Months     int         `json:"months"`
+		DecidedBy  string      `json:"decidedBy"`
+		SharedWith []uuid.UUID `json:"sharedWith,omitempty"`
 	} `json:"playerOfTheYear"`
 	LoserOfTheYear struct {
-		Uuid   uuid.UUID `json:"uuid"`
-		Months int       `json:"months"`
+		Uuid       uuid.UUID   `json:"uuid"`
+		Months     int         `json:"months"`
+		DecidedBy  string      `json:"decidedBy"`
+		SharedWith []uuid.UUID `json:"sharedWith,omitempty"`
 	} `json:"loserOfTheYear"`
 	BountyWinner struct {
-		Uuid      uuid.UUID `json:"uuid"`
-		Knockouts int       `json:"knockouts"`
+		Uuid       uuid.UUID   `json:"uuid"`
+		Knockouts  int         `json:"knockouts"`
+		DecidedBy  string      `json:"decidedBy"`
+		SharedWith []uuid.UUID `json:"sharedWith,omitempty"`
 	} `json:"bountyWinner"`
 }
 
-func BestPlayer(tournaments Tournaments) (PlayerStandings, bool) {
-	standings := NewStandings(tournaments)
-	standings.ByBestPlayer()
-
-	if standings[0].Results.Equals(standings[1].Results) {
-		// It's still a tie
-		return standings, true
-	}
-	return standings, false
-}
-
-func WorstPlayer(tournaments Tournaments) (PlayerStandings, bool) {
-	standings := NewStandings(tournaments)
-	standings.ByWorstPlayer()
-	if standings[0].Results.Equals(standings[1].Results) {
-		// It's still a tie
-		return standings, true
-	}
-	return standings, false
-}
-
-func BountyHunter(tournaments Tournaments) (PlayerStandings, bool) {
-	standings := NewStandings(tournaments)
-	standings.ByKnockouts()
-
-	if standings[0].Results.Equals(standings[1].Results) {
-		// It's still a tie
-		return standings, true
-	}
-	return standings, false
-}
-
-func YellowPeriods(tournaments Tournaments) []YellowPeriod {
-	var periods []YellowPeriod
-	var currentPeriod *YellowPeriod
+// Call fn with the standings after every played tournament, sorted by
+// winnings. Standings start from scratch on every new season.
+func prefixStandings(tournaments Tournaments, fn func(t *Tournament, standings PlayerStandings) error) error {
 	var season, seasonIndex int
 
 	sort.Stable(tournaments)
@@ -106,33 +91,109 @@ func YellowPeriods(tournaments Tournaments) []YellowPeriod {
 			season = tournaments[i].Info.Season
 			seasonIndex = i
 		}
-		standings := NewStandings(tournaments[seasonIndex : i+1])
+		standings := NewStandings(tournaments[seasonIndex:i+1], nil)
 		standings.ByWinnings(season < 2013)
+		if err := fn(tournaments[i], standings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func YellowPeriods(tournaments Tournaments) ([]YellowPeriod, error) {
+	var periods []YellowPeriod
+	var currentPeriod *YellowPeriod
+
+	err := prefixStandings(tournaments, func(t *Tournament, standings PlayerStandings) error {
+		// Ties for the lead are broken like for the champion
+		data := &tieBreakData{standings: standingsByPlayer(standings)}
+		d, err := data.decide(championRuleset(t.Info.Season), allPlayers(standings))
+		if err != nil {
+			return err
+		}
+		leader := d.Winner()
+
 		if currentPeriod == nil {
 			currentPeriod = &YellowPeriod{
-				From:   tournaments[i].Info.Scheduled,
-				To:     tournaments[i].Info.Scheduled,
-				Player: standings[0].Player,
+				From:   t.Info.Scheduled,
+				To:     t.Info.Scheduled,
+				Player: leader,
 				Active: true,
 			}
-		} else if currentPeriod.Player == standings[0].Player {
-			currentPeriod.To = tournaments[i].Info.Scheduled
+		} else if currentPeriod.Player == leader {
+			currentPeriod.To = t.Info.Scheduled
 		} else {
 			currentPeriod.Active = false
-			currentPeriod.To = tournaments[i].Info.Scheduled
+			currentPeriod.To = t.Info.Scheduled
 			periods = append(periods, *currentPeriod)
 			currentPeriod = &YellowPeriod{
-				From:   tournaments[i].Info.Scheduled,
-				To:     tournaments[i].Info.Scheduled,
-				Player: standings[0].Player,
+				From:   t.Info.Scheduled,
+				To:     t.Info.Scheduled,
+				Player: leader,
 				Active: true,
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	if currentPeriod != nil {
 		periods = append(periods, *currentPeriod)
 	}
-	return periods
+	return periods, nil
+}
+
+// A player's standing after a tournament
+type ProgressionPoint struct {
+	Tournament uuid.UUID `json:"tournament"`
+	When       time.Time `json:"when"`
+	Winnings   int       `json:"winnings"`
+	Points     int       `json:"points"`
+	Rank       int       `json:"rank"`
+	AvgPlace   float64   `json:"avgPlace"`
+}
+
+type Progression map[uuid.UUID][]ProgressionPoint
+
+// The standings of every player after every played tournament. Players
+// get their first point after their first tournament, and are ranked
+// like for the champion.
+func NewProgression(tournaments Tournaments) (Progression, error) {
+	progression := make(Progression)
+
+	err := prefixStandings(tournaments, func(t *Tournament, standings PlayerStandings) error {
+		byPlayer := standingsByPlayer(standings)
+		data := &tieBreakData{standings: byPlayer}
+		ordered, err := data.order(championRuleset(t.Info.Season), allPlayers(standings))
+		if err != nil {
+			return err
+		}
+		for i, player := range ordered {
+			ps := byPlayer[player]
+			progression[player] = append(progression[player], ProgressionPoint{
+				Tournament: t.UUID,
+				When:       t.Info.Scheduled,
+				Winnings:   ps.Winnings,
+				Points:     ps.Points,
+				Rank:       i + 1,
+				AvgPlace:   ps.AvgPlace,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return progression, nil
+}
+
+func SeasonProgression(season int) (Progression, error) {
+	data, err := cachedSeason(season)
+	if err != nil {
+		return nil, err
+	}
+	return data.progression, nil
 }
 
 func YellowDaysInSeason(yellowPeriods []YellowPeriod, season int) map[uuid.UUID]int {
@@ -163,263 +224,207 @@ func YellowDaysInSeason(yellowPeriods []YellowPeriod, season int) map[uuid.UUID]
 	return daysByPlayer
 }
 
-func mostYellowDaysInSeason(yellowPeriods []YellowPeriod, season int) (uuid.UUID, int) {
-	daysByPlayer := YellowDaysInSeason(yellowPeriods, season)
-	max := 0
-	var maxPlayer uuid.UUID
-	for p, d := range daysByPlayer {
-		if d > max {
-			max = d
-			maxPlayer = p
-		}
-	}
+func Titles(seasons []int) ([]*SeasonTitles, error) {
 
-	return maxPlayer, max
-}
-
-func loserOfTheYear(monthPeriods []*MonthStats, season int) ([]uuid.UUID, int) {
-	lastPlacesByPlayer := make(map[uuid.UUID]int)
-	playersByPlace := make(map[int][]uuid.UUID)
+	var titleList []*SeasonTitles
 
-	for _, mp := range monthPeriods {
-		if mp.Year != season {
-			continue
+	seasonStats, err := SeasonStats(seasons)
+	if err != nil {
+		return nil, err
+	}
+	for _, season := range seasons {
+		standings, err := cachedStandings(season)
+		if err != nil {
+			return nil, err
 		}
-		lastPlacesByPlayer[mp.Worst] += 1
+		titles, err := NewSeasonTitles(season, standings, seasonStats)
+		if err != nil {
+			return nil, err
+		}
+		titleList = append(titleList, titles)
 	}
+	return titleList, nil
+}
 
-	max := 0
-	for player, count := range lastPlacesByPlayer {
-		playersByPlace[count] = append(playersByPlace[count], player)
-		if count > max {
-			max = count
+// Count the months each player was best and worst of the season
+func monthCounts(monthStats []*MonthStats, season int) (map[uuid.UUID]int, map[uuid.UUID]int) {
+	best := make(map[uuid.UUID]int)
+	worst := make(map[uuid.UUID]int)
+	for _, ms := range monthStats {
+		if ms.Year != season {
+			continue
 		}
+		best[ms.Best] += 1
+		worst[ms.Worst] += 1
 	}
-
-	return playersByPlace[max], max
+	return best, worst
 }
 
-func playerOfTheYear(monthPeriods []*MonthStats, season int) ([]uuid.UUID, int) {
-	topPlacesByPlayer := make(map[uuid.UUID]int)
-	playersByPlace := make(map[int][]uuid.UUID)
+// The titles of a season given its standings and stats
+func NewSeasonTitles(season int, seasonStandings PlayerStandings, seasonStats *PeriodStats) (*SeasonTitles, error) {
+	titles := &SeasonTitles{Season: season}
 
-	for _, mp := range monthPeriods {
-		if mp.Year != season {
-			continue
-		}
-		topPlacesByPlayer[mp.Best] += 1
+	data := &tieBreakData{
+		standings:  standingsByPlayer(seasonStandings),
+		yellowDays: YellowDaysInSeason(seasonStats.YellowPeriods, season),
 	}
+	data.bestMonths, data.worstMonths = monthCounts(seasonStats.MonthStats, season)
+	enough := enoughPlayers(seasonStandings)
 
-	max := 0
-	for player, count := range topPlacesByPlayer {
-		playersByPlace[count] = append(playersByPlace[count], player)
-		if count > max {
-			max = count
-		}
+	d, err := data.decide(championRuleset(season), enough)
+	if err != nil {
+		return nil, err
+	}
+	titles.Champion.Uuid, titles.Champion.DecidedBy, titles.Champion.SharedWith = d.Winner(), d.DecidedBy, d.SharedWith()
+	if ps, found := data.standings[d.Winner()]; found {
+		titles.Champion.Winnings = ps.Winnings
 	}
 
-	return playersByPlace[max], max
-}
+	d, err = data.decide(Rulesets["avgPlaceWinner"], enough)
+	if err != nil {
+		return nil, err
+	}
+	titles.AvgPlaceWinner.Uuid, titles.AvgPlaceWinner.DecidedBy, titles.AvgPlaceWinner.SharedWith = d.Winner(), d.DecidedBy, d.SharedWith()
+	if ps, found := data.standings[d.Winner()]; found {
+		titles.AvgPlaceWinner.AvgPlace = ps.AvgPlace
+	}
 
-func Titles(seasons []int) []*SeasonTitles {
+	d, err = data.decide(Rulesets["playerOfTheYear"], countedPlayers(data.bestMonths))
+	if err != nil {
+		return nil, err
+	}
+	titles.PlayerOfTheYear.Uuid, titles.PlayerOfTheYear.DecidedBy, titles.PlayerOfTheYear.SharedWith = d.Winner(), d.DecidedBy, d.SharedWith()
+	titles.PlayerOfTheYear.Months = data.bestMonths[d.Winner()]
 
-	var titleList []*SeasonTitles
+	d, err = data.decide(Rulesets["loserOfTheYear"], countedPlayers(data.worstMonths))
+	if err != nil {
+		return nil, err
+	}
+	titles.LoserOfTheYear.Uuid, titles.LoserOfTheYear.DecidedBy, titles.LoserOfTheYear.SharedWith = d.Winner(), d.DecidedBy, d.SharedWith()
+	titles.LoserOfTheYear.Months = data.worstMonths[d.Winner()]
 
-	seasonStats := SeasonStats(seasons)
-	for _, season := range seasons {
-		titles := &SeasonTitles{Season: season}
-		t, _ := TournamentsBySeason(season)
-		seasonStandings := NewStandings(t)
-
-		seasonStandings.ByWinnings(season < 2013)
-
-		for i := range seasonStandings {
-			if seasonStandings[i].Enough {
-				p, w := seasonStandings[i].Player, seasonStandings[i].Winnings
-				titles.Champion.Uuid = p
-				titles.Champion.Winnings = w
-				break
-			}
-		}
+	d, err = data.decide(Rulesets["pointsWinner"], enough)
+	if err != nil {
+		return nil, err
+	}
+	titles.PointsWinner.Uuid, titles.PointsWinner.DecidedBy, titles.PointsWinner.SharedWith = d.Winner(), d.DecidedBy, d.SharedWith()
+	if ps, found := data.standings[d.Winner()]; found {
+		titles.PointsWinner.Points = ps.Points
+	}
 
-		seasonStandings.ByAvgPlace()
-		for i := range seasonStandings {
-			if seasonStandings[i].Enough {
-				p, ap := seasonStandings[i].Player, seasonStandings[i].AvgPlace
-				titles.AvgPlaceWinner.Uuid = p
-				titles.AvgPlaceWinner.AvgPlace = ap
-				break
-			}
+	if season >= 2019 {
+		d, err = data.decide(Rulesets["bountyWinner"], enough)
+		if err != nil {
+			return nil, err
 		}
-
-		players, c := playerOfTheYear(seasonStats.MonthStats, season)
-		if len(players) == 1 {
-			titles.PlayerOfTheYear.Uuid = players[0]
-		} else {
-		POTYLoop:
-			for _, p := range players {
-				for _, s := range seasonStandings {
-					if s.Player == p && s.Enough {
-						titles.PlayerOfTheYear.Uuid = p
-						break POTYLoop
-					}
-				}
-			}
+		titles.BountyWinner.Uuid, titles.BountyWinner.DecidedBy, titles.BountyWinner.SharedWith = d.Winner(), d.DecidedBy, d.SharedWith()
+		if ps, found := data.standings[d.Winner()]; found {
+			titles.BountyWinner.Knockouts = ps.Knockouts
 		}
-		titles.PlayerOfTheYear.Months = c
+	}
 
-		players, c = loserOfTheYear(seasonStats.MonthStats, season)
-		if len(players) == 1 {
-			titles.LoserOfTheYear.Uuid = players[0]
-		} else {
-		LOTYLoop:
-			for _, p := range players {
-				for i := len(seasonStandings) - 1; i >= 0; i-- {
-					if seasonStandings[i].Player == p && seasonStandings[i].Enough {
-						titles.LoserOfTheYear.Uuid = p
-						break LOTYLoop
-					}
-				}
-			}
-		}
+	d, err = data.decide(Rulesets["mostYellowDays"], countedPlayers(data.yellowDays))
+	if err != nil {
+		return nil, err
+	}
+	titles.MostYellowDays.Uuid, titles.MostYellowDays.DecidedBy, titles.MostYellowDays.SharedWith = d.Winner(), d.DecidedBy, d.SharedWith()
+	titles.MostYellowDays.Days = data.yellowDays[d.Winner()]
 
-		titles.LoserOfTheYear.Months = c
+	return titles, nil
+}
 
-		seasonStandings.ByPoints()
-		for i := range seasonStandings {
-			if seasonStandings[i].Enough {
-				p, pnts := seasonStandings[i].Player, seasonStandings[i].Points
-				titles.PointsWinner.Uuid = p
-				titles.PointsWinner.Points = pnts
-				break
-			}
-		}
+// Stats for the tournaments of a single season
+func NewSeasonStats(tournaments Tournaments, season int) (*PeriodStats, error) {
+	yellows, err := YellowPeriods(tournaments)
+	if err != nil {
+		return nil, err
+	}
+	monthStats, err := seasonMonthStats(tournaments, season)
+	if err != nil {
+		return nil, err
+	}
+	return &PeriodStats{YellowPeriods: yellows, MonthStats: monthStats}, nil
+}
 
-		if season >= 2019 {
-			seasonStandings.ByKnockouts()
-			for i := range seasonStandings {
-				if seasonStandings[i].Enough {
-					p, knockouts := seasonStandings[i].Player, seasonStandings[i].Knockouts
-					titles.BountyWinner.Uuid = p
-					titles.BountyWinner.Knockouts = knockouts
-					break
-				}
-			}
+func SeasonStats(seasons []int) (*PeriodStats, error) {
+	stats := new(PeriodStats)
+
+	sorted := append([]int{}, seasons...)
+	sort.Ints(sorted)
+	for _, season := range sorted {
+		data, err := cachedSeason(season)
+		if err != nil {
+			return nil, err
 		}
-		// FIXME: This is missing tie breaks..
-		p, d := mostYellowDaysInSeason(seasonStats.YellowPeriods, season)
-		titles.MostYellowDays.Uuid = p
-		titles.MostYellowDays.Days = d
+		stats.YellowPeriods = joinYellowPeriods(stats.YellowPeriods, data.yellows)
+		for _, ms := range data.monthStats {
+			stats.MonthStats = append(stats.MonthStats, ms.copy())
+		}
+	}
+	return stats, nil
+}
 
-		titleList = append(titleList, titles)
+// Copy month stats so callers can modify them without touching the cache
+func (ms *MonthStats) copy() *MonthStats {
+	c := *ms
+	c.DecidedBy = make(map[string]string, len(ms.DecidedBy))
+	for k, v := range ms.DecidedBy {
+		c.DecidedBy[k] = v
 	}
-	return titleList
+	return &c
 }
 
-func SeasonStats(seasons []int) *PeriodStats {
-	stats := new(PeriodStats)
-	all, err := AllTournaments()
-	if err != nil {
-		// TODO
+func seasonMonthStats(t Tournaments, season int) ([]*MonthStats, error) {
+	var stats []*MonthStats
+
+	byMonth := t.GroupByMonths(season)
+	var sortedMonths []int
+	for k := range byMonth {
+		sortedMonths = append(sortedMonths, int(k))
 	}
+	sort.Ints(sortedMonths)
 
-	var t Tournaments
-	for _, event := range all {
-		for _, s := range seasons {
-			if event.Info.Season == s {
-				t = append(t, event)
-			}
+	var yearToDate Tournaments
+	for _, i := range sortedMonths {
+		v := byMonth[time.Month(i)]
+		yearToDate = append(yearToDate, v...)
+		if len(v.Played()) == 0 {
+			continue
 		}
-	}
 
-	yellows := YellowPeriods(t)
-	stats.YellowPeriods = yellows
+		standings := NewStandings(v, nil)
+		data := &tieBreakData{
+			standings:  standingsByPlayer(standings),
+			yearToDate: standingsByPlayer(NewStandings(yearToDate, nil)),
+		}
+		players := allPlayers(standings)
 
-	for _, season := range seasons {
-		byMonth := t.GroupByMonths(season)
-		var sortedMonths []int
-		for k := range byMonth {
-			sortedMonths = append(sortedMonths, int(k))
+		monthStats := &MonthStats{
+			Year:      season,
+			Month:     time.Month(i),
+			DecidedBy: make(map[string]string),
 		}
-		sort.Ints(sortedMonths)
-		for _, i := range sortedMonths {
-			v := byMonth[time.Month(i)]
-			monthStats := new(MonthStats)
 
-			played := v.Played()
+		d, err := data.decide(Rulesets["monthBest"], players)
+		if err != nil {
+			return nil, err
+		}
+		monthStats.Best, monthStats.DecidedBy["best"] = d.Winner(), d.DecidedBy
 
-			if len(played) == 0 {
-				continue
-			}
+		d, err = data.decide(Rulesets["monthWorst"], players)
+		if err != nil {
+			return nil, err
+		}
+		monthStats.Worst, monthStats.DecidedBy["worst"] = d.Winner(), d.DecidedBy
 
-			sort.Stable(v)
-			monthStats.Year = season
-			monthStats.Month = time.Month(i)
-
-			best, tie := BestPlayer(v)
-			bestplayer := best[0].Player
-			if tie {
-				var tiebreakTournaments Tournaments
-				for j := 1; j <= i; j++ {
-					tiebreakTournaments = append(tiebreakTournaments, byMonth[time.Month(j)]...)
-				}
-				tiebest, tie := BestPlayer(tiebreakTournaments)
-				if tie {
-					println("    Warning: Tied for best player for month", i, "in year", season)
-				}
-				for p := range tiebest {
-					if tiebest[p].Player == best[0].Player || tiebest[p].Player == best[1].Player {
-						bestplayer = tiebest[p].Player
-						break
-					}
-				}
-			}
-			monthStats.Best = bestplayer
-
-			worst, tie := WorstPlayer(v)
-			worstplayer := worst[0].Player
-			if tie {
-				var tiebreakTournaments Tournaments
-				for j := 1; j <= int(i); j++ {
-					tiebreakTournaments = append(tiebreakTournaments, byMonth[time.Month(j)]...)
-				}
-
-				tieworst, tie := WorstPlayer(tiebreakTournaments)
-				if tie {
-					println("    Warning: Tied for worst player for month", i, "in year", season)
-				}
-				for p := range tieworst {
-					if tieworst[p].Player == worst[0].Player || tieworst[p].Player == worst[1].Player {
-						worstplayer = tieworst[p].Player
-						break
-					}
-				}
-			}
-			monthStats.Worst = worstplayer
-
-			if season >= 2019 {
-				bh, tie := BountyHunter(v)
-				bountyhunter := bh[0].Player
-				if tie {
-					var tiebreakTournaments Tournaments
-					for j := 1; j <= i; j++ {
-						tiebreakTournaments = append(tiebreakTournaments, byMonth[time.Month(j)]...)
-					}
-					tiebh, tie := BountyHunter(tiebreakTournaments)
-					if tie {
-						println("    Warning: Tied for bounty hunter of the month", i, "in year", season)
-					}
-					for p := range tiebh {
-						if tiebh[p].Player == bh[0].Player || tiebh[p].Player == bh[1].Player {
-							bountyhunter = tiebh[p].Player
-							break
-						}
-					}
-				}
-				monthStats.BountyHunter = bountyhunter
+		if season >= 2019 {
+			d, err = data.decide(Rulesets["monthBountyHunter"], players)
+			if err != nil {
+				return nil, err
 			}
-			stats.MonthStats = append(stats.MonthStats, monthStats)
+			monthStats.BountyHunter, monthStats.DecidedBy["bountyhunter"] = d.Winner(), d.DecidedBy
 		}
+		stats = append(stats, monthStats)
 	}
-	return stats
+	return stats, nil
 }
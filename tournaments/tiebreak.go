@@ -0,0 +1,289 @@
+package tournaments
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/m4rw3r/uuid"
+)
+
+// A tie-break criterion. Compare returns a negative number when a is
+// better than b, a positive number when b is better and 0 when they
+// are still tied.
+type Criterion struct {
+	Name    string
+	Compare func(a, b uuid.UUID) int
+}
+
+// An ordered chain of criteria deciding an award. Players still tied
+// after the last criterion share the award if the ruleset says so,
+// otherwise the tie is broken by player UUID to keep the outcome stable.
+type Ruleset struct {
+	Criteria []string `json:"criteria"`
+	Shared   bool     `json:"shared"`
+}
+
+const FallbackCriterion = "fallback"
+const SharedCriterion = "shared"
+
+// Rulesets for the season titles and monthly awards. Criteria prefixed
+// with yearToDate use all tournaments of the season up to and
+// including the month.
+var Rulesets = map[string]Ruleset{
+	"champion":          {Criteria: []string{"winnings", "points", "wins"}},
+	"championOld":       {Criteria: []string{"winnings", "avgPlace", "wins"}},
+	"avgPlaceWinner":    {Criteria: []string{"avgPlace", "winnings", "wins"}},
+	"pointsWinner":      {Criteria: []string{"points", "winnings", "wins"}},
+	"bountyWinner":      {Criteria: []string{"knockouts", "winnings", "wins"}},
+	"mostYellowDays":    {Criteria: []string{"yellowDays"}, Shared: true},
+	"playerOfTheYear":   {Criteria: []string{"bestMonths", "playedEnough", "avgPlace", "winnings", "wins"}},
+	"loserOfTheYear":    {Criteria: []string{"worstMonths", "playedEnough", "worstAvgPlace", "worstResults"}},
+	"monthBest":         {Criteria: []string{"avgPlace", "results", "yearToDateAvgPlace", "yearToDateResults"}},
+	"monthWorst":        {Criteria: []string{"worstAvgPlace", "worstResults", "yearToDateWorstAvgPlace", "yearToDateWorstResults"}},
+	"monthBountyHunter": {Criteria: []string{"knockouts", "winnings", "wins", "yearToDateKnockouts"}},
+}
+
+// The outcome of an award. DecidedBy names the criterion that separated
+// the winner from the rest.
+type Decision struct {
+	Winners   []uuid.UUID
+	DecidedBy string
+}
+
+func (d Decision) Winner() uuid.UUID {
+	if len(d.Winners) == 0 {
+		return uuid.UUID{}
+	}
+	return d.Winners[0]
+}
+
+func (d Decision) SharedWith() []uuid.UUID {
+	if len(d.Winners) < 2 {
+		return nil
+	}
+	return d.Winners[1:]
+}
+
+// The data criteria are computed from
+type tieBreakData struct {
+	standings   map[uuid.UUID]*PlayerStanding
+	yearToDate  map[uuid.UUID]*PlayerStanding
+	yellowDays  map[uuid.UUID]int
+	bestMonths  map[uuid.UUID]int
+	worstMonths map[uuid.UUID]int
+}
+
+func standingsByPlayer(standings PlayerStandings) map[uuid.UUID]*PlayerStanding {
+	byPlayer := make(map[uuid.UUID]*PlayerStanding)
+	for _, ps := range standings {
+		byPlayer[ps.Player] = ps
+	}
+	return byPlayer
+}
+
+func compareInts(a, b int) int {
+	return a - b
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareResults(a, b PlayerResults) int {
+	switch {
+	case a.BetterThan(b):
+		return -1
+	case b.BetterThan(a):
+		return 1
+	}
+	return 0
+}
+
+func compareCounts(counts map[uuid.UUID]int) func(a, b uuid.UUID) int {
+	return func(a, b uuid.UUID) int { return compareInts(counts[b], counts[a]) }
+}
+
+func compareStandings(standings map[uuid.UUID]*PlayerStanding, cmp func(a, b *PlayerStanding) int) func(a, b uuid.UUID) int {
+	empty := new(PlayerStanding)
+	return func(a, b uuid.UUID) int {
+		sa, sb := standings[a], standings[b]
+		if sa == nil {
+			sa = empty
+		}
+		if sb == nil {
+			sb = empty
+		}
+		return cmp(sa, sb)
+	}
+}
+
+func (d *tieBreakData) criterion(name string) (Criterion, error) {
+	standings := d.standings
+	key := name
+	if strings.HasPrefix(name, "yearToDate") {
+		standings = d.yearToDate
+		key = strings.ToLower(name[10:11]) + name[11:]
+	}
+
+	var cmp func(a, b uuid.UUID) int
+	switch key {
+	case "winnings":
+		cmp = compareStandings(standings, func(a, b *PlayerStanding) int { return compareInts(b.Winnings, a.Winnings) })
+	case "points":
+		cmp = compareStandings(standings, func(a, b *PlayerStanding) int { return compareInts(a.Points, b.Points) })
+	case "wins":
+		cmp = compareStandings(standings, func(a, b *PlayerStanding) int { return compareInts(b.NumWins, a.NumWins) })
+	case "avgPlace":
+		cmp = compareStandings(standings, func(a, b *PlayerStanding) int { return compareFloats(a.AvgPlace, b.AvgPlace) })
+	case "worstAvgPlace":
+		cmp = compareStandings(standings, func(a, b *PlayerStanding) int { return compareFloats(b.AvgPlace, a.AvgPlace) })
+	case "results":
+		cmp = compareStandings(standings, func(a, b *PlayerStanding) int { return compareResults(a.Results, b.Results) })
+	case "worstResults":
+		cmp = compareStandings(standings, func(a, b *PlayerStanding) int { return compareResults(b.Results, a.Results) })
+	case "knockouts":
+		cmp = compareStandings(standings, func(a, b *PlayerStanding) int { return compareInts(b.Knockouts, a.Knockouts) })
+	case "playedEnough":
+		cmp = compareStandings(standings, func(a, b *PlayerStanding) int {
+			if a.Enough == b.Enough {
+				return 0
+			}
+			if a.Enough {
+				return -1
+			}
+			return 1
+		})
+	case "yellowDays":
+		cmp = compareCounts(d.yellowDays)
+	case "bestMonths":
+		cmp = compareCounts(d.bestMonths)
+	case "worstMonths":
+		cmp = compareCounts(d.worstMonths)
+	default:
+		return Criterion{}, errors.New("Unknown tie-break criterion " + name)
+	}
+	return Criterion{Name: name, Compare: cmp}, nil
+}
+
+// Check that every criterion of the ruleset is known
+func (r Ruleset) Validate() error {
+	data := new(tieBreakData)
+	for _, name := range r.Criteria {
+		if _, err := data.criterion(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	for name, r := range Rulesets {
+		if err := r.Validate(); err != nil {
+			panic(err.Error() + " - Invalid ruleset " + name)
+		}
+	}
+}
+
+// Decide an award among the candidates by applying the criteria of the
+// ruleset in order to the players still tied
+func (d *tieBreakData) decide(ruleset Ruleset, candidates []uuid.UUID) (Decision, error) {
+	if len(candidates) == 0 {
+		return Decision{}, nil
+	}
+
+	tied := append([]uuid.UUID{}, candidates...)
+	for _, name := range ruleset.Criteria {
+		c, err := d.criterion(name)
+		if err != nil {
+			return Decision{}, err
+		}
+		best := tied[0]
+		for _, p := range tied[1:] {
+			if c.Compare(p, best) < 0 {
+				best = p
+			}
+		}
+		var still []uuid.UUID
+		for _, p := range tied {
+			if c.Compare(p, best) == 0 {
+				still = append(still, p)
+			}
+		}
+		tied = still
+		if len(tied) == 1 {
+			return Decision{Winners: tied, DecidedBy: name}, nil
+		}
+	}
+
+	sort.Slice(tied, func(i, j int) bool { return tied[i].String() < tied[j].String() })
+	if ruleset.Shared {
+		return Decision{Winners: tied, DecidedBy: SharedCriterion}, nil
+	}
+	return Decision{Winners: tied[:1], DecidedBy: FallbackCriterion}, nil
+}
+
+// Order the players by the criteria of the ruleset, breaking remaining
+// ties by player UUID
+func (d *tieBreakData) order(ruleset Ruleset, players []uuid.UUID) ([]uuid.UUID, error) {
+	var criteria []Criterion
+	for _, name := range ruleset.Criteria {
+		c, err := d.criterion(name)
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, c)
+	}
+	ordered := append([]uuid.UUID{}, players...)
+	sort.Slice(ordered, func(i, j int) bool {
+		for _, c := range criteria {
+			if cmp := c.Compare(ordered[i], ordered[j]); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return ordered[i].String() < ordered[j].String()
+	})
+	return ordered, nil
+}
+
+// The ruleset deciding the champion, which changed in 2013
+func championRuleset(season int) Ruleset {
+	if season < 2013 {
+		return Rulesets["championOld"]
+	}
+	return Rulesets["champion"]
+}
+
+func enoughPlayers(standings PlayerStandings) []uuid.UUID {
+	var players []uuid.UUID
+	for _, ps := range standings {
+		if ps.Enough {
+			players = append(players, ps.Player)
+		}
+	}
+	return players
+}
+
+func countedPlayers(counts map[uuid.UUID]int) []uuid.UUID {
+	var players []uuid.UUID
+	for p, c := range counts {
+		if c > 0 {
+			players = append(players, p)
+		}
+	}
+	return players
+}
+
+func allPlayers(standings PlayerStandings) []uuid.UUID {
+	var players []uuid.UUID
+	for _, ps := range standings {
+		players = append(players, ps.Player)
+	}
+	return players
+}
@@ -10,8 +10,9 @@ import (
 	"github.com/m4rw3r/uuid"
 )
 
-// We use dummy in memory storage for now
-var storage TournamentStorage = NewRedisTournamentStorage()
+// We use dummy in memory storage for now, wrapped to keep the
+// standings and stats cache up to date
+var storage TournamentStorage = &cachingStorage{NewRedisTournamentStorage()}
 
 // Init a message queue
 var eventqueue utils.AMQPQueue = utils.NewRMQ(os.Getenv("CKPT_AMQP_URL"), "ckpt.events")
@@ -27,6 +28,8 @@ type Result []uuid.UUID
 type Bet struct {
 	Player     uuid.UUID `json:"player"`
 	Prediction Result    `json:"prediction"`
+	Placed     time.Time `json:"placed"`
+	Updated    time.Time `json:"updated"`
 }
 
 type BountyHunters map[uuid.UUID][]uuid.UUID
@@ -41,14 +44,23 @@ type Info struct {
 }
 
 type Tournament struct {
-	UUID          uuid.UUID     `json:"uuid"`
-	Info          Info          `json:"info"`
-	Noshows       []Absentee    `json:"noshows"`
-	Result        Result        `json:"result"`
-	Played        bool          `json:"played"`
-	Moved         bool          `json:"moved"`
-	Bets          []Bet         `json:"bets"`
-	BountyHunters BountyHunters `json:"bountyHunters"`
+	UUID          uuid.UUID          `json:"uuid"`
+	Info          Info               `json:"info"`
+	Noshows       []Absentee         `json:"noshows"`
+	Result        Result             `json:"result"`
+	Played        bool               `json:"played"`
+	Moved         bool               `json:"moved"`
+	Bets          []Bet              `json:"bets"`
+	BountyHunters BountyHunters      `json:"bountyHunters"`
+	Entries       []Entry            `json:"entries"`
+	RSVPs         []RSVP             `json:"rsvps"`
+	Reschedules   []Reschedule       `json:"reschedules"`
+	Blinds        []BlindLevel       `json:"blinds"`
+	Clock         *Clock             `json:"clock"`
+	Eliminations  []Elimination      `json:"eliminations"`
+	CheckedIn     []uuid.UUID        `json:"checkedIn"`
+	Seating       *Seating           `json:"seating"`
+	Provisional   *ProvisionalResult `json:"provisional"`
 }
 
 type Tournaments []*Tournament
@@ -64,6 +76,10 @@ type TournamentStorage interface {
 	Load(uuid.UUID) (*Tournament, error)
 	LoadAll() (Tournaments, error)
 	LoadBySeason(int) (Tournaments, error)
+	StoreRecurrence(*Recurrence) error
+	LoadRecurrence(int) (*Recurrence, error)
+	StoreBetScoring(int, *BetScoring) error
+	LoadBetScoring(int) (*BetScoring, error)
 }
 
 //
@@ -220,9 +236,15 @@ func (t *Tournament) SetPlayed(isPlayed bool) error {
 	return nil
 }
 
-func (t *Tournament) SetResult(result Result) error {
+// Set the result of the tournament. If known is given, every player in
+// the result must be in it.
+func (t *Tournament) SetResult(result Result, known []uuid.UUID) error {
+	if err := t.ValidateResult(result, known); err != nil {
+		return err
+	}
 	t.Played = true
 	t.Result = result
+	t.reconcileNoShows()
 	err := storage.Store(t)
 	if err != nil {
 		return errors.New(err.Error() + " - Could not store tournament result")
@@ -235,6 +257,9 @@ func (t *Tournament) SetResult(result Result) error {
 }
 
 func (t *Tournament) SetBountyHunters(bh BountyHunters) error {
+	if err := ValidateBountyHunters(bh, t.FinishOrder()); err != nil {
+		return err
+	}
 	t.Played = true
 	t.BountyHunters = bh
 	err := storage.Store(t)
@@ -0,0 +1,128 @@
+package tournaments
+
+import (
+	"errors"
+
+	"github.com/m4rw3r/uuid"
+)
+
+// How a player's standing by winnings would change with the
+// hypothetical results. RankChange is positive when moving up.
+type StandingDiff struct {
+	Player         uuid.UUID `json:"uuid"`
+	Rank           int       `json:"rank"`
+	RankChange     int       `json:"rankChange"`
+	Winnings       int       `json:"winnings"`
+	WinningsChange int       `json:"winningsChange"`
+	Points         int       `json:"points"`
+	PointsChange   int       `json:"pointsChange"`
+}
+
+type WhatIf struct {
+	Season    int              `json:"season"`
+	Standings *SortedStandings `json:"standings"`
+	Titles    *SeasonTitles    `json:"titles"`
+	Stats     *PeriodStats     `json:"stats"`
+	Diff      []StandingDiff   `json:"diff"`
+}
+
+func validateHypotheticalResult(result Result) error {
+	if len(result) == 0 {
+		return errors.New("Result can not be empty")
+	}
+	seen := make(map[uuid.UUID]bool)
+	for _, p := range result {
+		if seen[p] {
+			return errors.New("Result contains the same player more than once")
+		}
+		seen[p] = true
+	}
+	return nil
+}
+
+// The season as it would be with the given results for unplayed
+// tournaments. Nothing is stored.
+func hypotheticalSeason(season int, results map[uuid.UUID]Result) (Tournaments, error) {
+	tList, err := TournamentsBySeason(season)
+	if err != nil {
+		return nil, err
+	}
+
+	var hypothetical Tournaments
+	used := 0
+	for _, t := range tList {
+		result, found := results[t.UUID]
+		if !found {
+			hypothetical = append(hypothetical, t)
+			continue
+		}
+		if t.Played {
+			return nil, errors.New("Tournament " + t.UUID.String() + " is already played")
+		}
+		if err := validateHypotheticalResult(result); err != nil {
+			return nil, errors.New(err.Error() + " - Invalid result for tournament " + t.UUID.String())
+		}
+		copied := *t
+		copied.Played = true
+		copied.Result = result
+		hypothetical = append(hypothetical, &copied)
+		used += 1
+	}
+	if used != len(results) {
+		return nil, errors.New("Results given for tournaments not in the season")
+	}
+	return hypothetical, nil
+}
+
+func standingsDiff(current, hypothetical PlayerStandings) []StandingDiff {
+	diff := []StandingDiff{}
+	for i, ps := range hypothetical {
+		d := StandingDiff{
+			Player:         ps.Player,
+			Rank:           i + 1,
+			Winnings:       ps.Winnings,
+			WinningsChange: ps.Winnings,
+			Points:         ps.Points,
+			PointsChange:   ps.Points,
+		}
+		for j, cps := range current {
+			if cps.Player == ps.Player {
+				d.RankChange = (j + 1) - d.Rank
+				d.WinningsChange = ps.Winnings - cps.Winnings
+				d.PointsChange = ps.Points - cps.Points
+			}
+		}
+		diff = append(diff, d)
+	}
+	return diff
+}
+
+// Compute standings, titles and stats of a season with hypothetical
+// results for some of the unplayed tournaments
+func SeasonWhatIf(season int, results map[uuid.UUID]Result) (*WhatIf, error) {
+	tList, err := hypotheticalSeason(season, results)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := NewSeasonStats(tList, season)
+	if err != nil {
+		return nil, err
+	}
+	titles, err := NewSeasonTitles(season, NewStandings(tList, nil), stats)
+	if err != nil {
+		return nil, err
+	}
+	whatIf := &WhatIf{
+		Season:    season,
+		Standings: sortStandings(NewStandings(tList, cachedRatings()), season < 2013),
+		Titles:    titles,
+		Stats:     stats,
+	}
+	current, err := SeasonStandings(season)
+	if err != nil {
+		return nil, err
+	}
+	whatIf.Diff = standingsDiff(current.ByWinnings, whatIf.Standings.ByWinnings)
+	return whatIf, nil
+}
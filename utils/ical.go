@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// A single VEVENT in an iCalendar feed
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	Updated     time.Time
+	HasGeo      bool
+	Lat         float64
+	Long        float64
+}
+
+const icalTimeFormat = "20060102T150405Z"
+
+var icalEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	";", "\\;",
+	",", "\\,",
+	"\r\n", "\\n",
+	"\n", "\\n",
+)
+
+func icalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+// Lines longer than 75 octets must be folded (RFC 5545, 3.1)
+func writeICalLine(w *bufio.Writer, line string) {
+	for len(line) > 75 {
+		cut := 75
+		// Do not split multi-byte UTF-8 characters
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n")
+		line = " " + line[cut:]
+	}
+	w.WriteString(line + "\r\n")
+}
+
+func WriteICal(out io.Writer, name string, events []ICalEvent) error {
+	w := bufio.NewWriter(out)
+	writeICalLine(w, "BEGIN:VCALENDAR")
+	writeICalLine(w, "VERSION:2.0")
+	writeICalLine(w, "PRODID:-//CKPT//backend-services//NO")
+	writeICalLine(w, "CALSCALE:GREGORIAN")
+	writeICalLine(w, "METHOD:PUBLISH")
+	writeICalLine(w, "X-WR-CALNAME:"+icalText(name))
+
+	now := time.Now().UTC().Format(icalTimeFormat)
+	for _, e := range events {
+		writeICalLine(w, "BEGIN:VEVENT")
+		writeICalLine(w, "UID:"+e.UID)
+		if e.Updated.IsZero() {
+			writeICalLine(w, "DTSTAMP:"+now)
+		} else {
+			writeICalLine(w, "DTSTAMP:"+e.Updated.UTC().Format(icalTimeFormat))
+		}
+		writeICalLine(w, "DTSTART:"+e.Start.UTC().Format(icalTimeFormat))
+		writeICalLine(w, "DTEND:"+e.End.UTC().Format(icalTimeFormat))
+		writeICalLine(w, "SUMMARY:"+icalText(e.Summary))
+		if e.Description != "" {
+			writeICalLine(w, "DESCRIPTION:"+icalText(e.Description))
+		}
+		if e.Location != "" {
+			writeICalLine(w, "LOCATION:"+icalText(e.Location))
+		}
+		if e.HasGeo {
+			writeICalLine(w, fmt.Sprintf("GEO:%f;%f", e.Lat, e.Long))
+		}
+		writeICalLine(w, "END:VEVENT")
+	}
+	writeICalLine(w, "END:VCALENDAR")
+	return w.Flush()
+}